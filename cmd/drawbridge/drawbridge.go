@@ -4,13 +4,14 @@ import (
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"os"
-	"regexp"
 	"time"
 
 	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/audit"
 	"github.com/analogj/drawbridge/pkg/config"
 	"github.com/analogj/drawbridge/pkg/errors"
 	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/transport"
 	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/analogj/drawbridge/pkg/version"
 	"github.com/fatih/color"
@@ -34,7 +35,7 @@ func main() {
 	if _, ok := err.(errors.ConfigFileMissingError); ok { // Handle errors reading the config file
 		//ignore "could not find config file"
 	} else if err != nil {
-		os.Exit(1)
+		os.Exit(errors.ExitCode(err))
 	}
 
 	createFlags, err := createFlags(config)
@@ -42,6 +43,16 @@ func main() {
 		fmt.Printf("FATAL: %+v\n", err)
 		os.Exit(1)
 	}
+	editFlags, err := questionFlags(config, false)
+	if err != nil {
+		fmt.Printf("FATAL: %+v\n", err)
+		os.Exit(1)
+	}
+	editFlags = append(editFlags, &cli.BoolFlag{
+		Name:  "dryrun",
+		Usage: "Dry Run mode. Will print the diff of the changed files rather than writing them to disk.",
+		Value: false,
+	})
 
 	cli.CommandHelpTemplate = `NAME:
    {{.HelpName}} - {{.Usage}}
@@ -67,7 +78,30 @@ OPTIONS:
 				Email: "jason@thesparktree.com",
 			},
 		},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "non-interactive",
+				Usage:   "Never prompt for input, fail with an error instead. Enabled automatically when STDIN is not a terminal",
+				EnvVars: []string{"DRAWBRIDGE_NON_INTERACTIVE"},
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format for list, check, ping, exec, history, replay, key list, agent list, tunnel status & create --dryrun: text, json or yaml",
+				Value:   utils.OutputFormatText,
+				EnvVars: []string{"DRAWBRIDGE_OUTPUT"},
+			},
+		},
 		Before: func(c *cli.Context) error {
+			utils.NonInteractive = c.Bool("non-interactive") || !utils.StdinIsTerminal()
+
+			if err := utils.ValidateOutputFormat(c.String("output")); err != nil {
+				return err
+			}
+			if utils.IsStructuredOutput(c.String("output")) {
+				//keep STDOUT machine-readable, the banner & informational messages are written to STDERR instead.
+				c.App.Writer = os.Stderr
+			}
 
 			drawbridge := "github.com/AnalogJ/drawbridge"
 
@@ -112,30 +146,43 @@ OPTIONS:
 					}
 
 					answerData := map[string]interface{}{}
-					if projectList.Length() > 0 && utils.StdinQueryBoolean(fmt.Sprintf("Would you like to create a Drawbridge config using preconfigured answers? (%v available). [yes/no]", projectList.Length())) {
-
-						answerData, _, err = projectList.Prompt("Enter number to base your configuration from")
+					//preconfigured answers are optional, in non-interactive mode all answers must be provided as flags.
+					if projectList.Length() > 0 && !utils.NonInteractive {
+						usePreconfigured, err := utils.StdinQueryBoolean(fmt.Sprintf("Would you like to create a Drawbridge config using preconfigured answers? (%v available). [yes/no]", projectList.Length()))
 						if err != nil {
 							return err
 						}
+						if usePreconfigured {
+							answerData, _, err = projectList.Prompt("Enter number to base your configuration from")
+							if err != nil {
+								return err
+							}
+						}
 					}
 
 					//extend current answerData with CLI provided options.
-					cliAnswers, err := createFlagHandler(config, answerData, c.FlagNames(), c)
+					cliAnswers, err := createFlagHandler(config, answerData, c.LocalFlagNames(), c)
 					if err != nil {
 						return err
 					}
 
 					createAction := actions.CreateAction{Config: config}
+					if c.Bool("dryrun") && utils.IsStructuredOutput(c.String("output")) {
+						rendered, err := createAction.Render(cliAnswers)
+						if err != nil {
+							return err
+						}
+						return utils.PrintStructured(os.Stdout, c.String("output"), rendered)
+					}
 					return createAction.Start(cliAnswers, c.Bool("dryrun"))
 				},
 
 				Flags: createFlags,
 			},
 			{
-				Name:      "list",
-				Usage:     "List all drawbridge managed ssh configs",
-				ArgsUsage: "[config_number/alias]",
+				Name:      "edit",
+				Usage:     "Change the answers of a drawbridge managed ssh config, and re-render its files",
+				ArgsUsage: "[config_number/id/alias/selector]",
 				Action: func(c *cli.Context) error {
 					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
@@ -147,30 +194,104 @@ OPTIONS:
 					var answerData map[string]interface{}
 					if c.NArg() > 0 {
 						answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+					} else {
+						answerData, _, err = projectList.Prompt("Enter drawbridge config number to edit")
+					}
+					if err != nil {
+						return err
+					}
+
+					//only the answers provided as flags are changed, otherwise every question is prompted for.
+					cliAnswers, err := questionFlagHandler(config, c.LocalFlagNames(), c)
+					if err != nil {
+						return err
+					}
+
+					editAction := actions.EditAction{Config: config}
+					return editAction.Start(answerData, cliAnswers, c.Bool("dryrun"))
+				},
+				Flags: editFlags,
+			},
+			{
+				Name:      "list",
+				Usage:     "List all drawbridge managed ssh configs",
+				ArgsUsage: "[config_number/id/alias/selector]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					if utils.IsStructuredOutput(c.String("output")) {
+						if len(c.StringSlice("tag")) > 0 {
+							entries, err := projectList.GetAllEntriesWithTags(c.Args().Get(0), c.StringSlice("tag"))
+							if err != nil {
+								return err
+							}
+							return utils.PrintStructured(os.Stdout, c.String("output"), entries)
+						} else if c.NArg() > 0 && project.IsSelector(c.Args().Get(0)) {
+							entries, err := projectList.GetAllEntriesWithAliasOrIndex(c.Args().Get(0))
+							if err != nil {
+								return err
+							}
+							return utils.PrintStructured(os.Stdout, c.String("output"), entries)
+						} else if c.NArg() > 0 {
+							entry, err := projectList.GetEntryWithAliasOrIndex(c.Args().Get(0))
+							if err != nil {
+								return err
+							}
+							return utils.PrintStructured(os.Stdout, c.String("output"), entry)
+						}
+						return utils.PrintStructured(os.Stdout, c.String("output"), projectList.GetAllEntries())
+					}
+
+					answerDataList := []map[string]interface{}{}
+					if len(c.StringSlice("tag")) > 0 {
+						answerDataList, _, err = projectList.GetAllWithTags(c.Args().Get(0), c.StringSlice("tag"))
+						if err != nil {
+							return err
+						}
+
+					} else if c.NArg() > 0 {
+						answerDataList, _, err = projectList.GetAllWithAliasOrIndex(c.Args().Get(0))
 						if err != nil {
 							return err
 						}
 
 					} else {
-						answerData, _, err = projectList.Prompt("Enter drawbridge config number or alias to retrieve full info")
+						answerData, _, err := projectList.Prompt("Enter drawbridge config number or alias to retrieve full info")
 						if err != nil {
 							return err
 						}
+						answerDataList = append(answerDataList, answerData)
 					}
 
-					fmt.Print("\nAnswer Data:\n")
-					for k, v := range answerData {
-						fmt.Printf("\t%v: %v\n", color.YellowString(k), v)
+					for _, answerData := range answerDataList {
+						fmt.Print("\nAnswer Data:\n")
+						for k, v := range answerData {
+							fmt.Printf("\t%v: %v\n", color.YellowString(k), v)
+						}
+
+						if hops := project.HopChain(answerData); len(hops) > 0 {
+							fmt.Printf("\nHop Chain:\n\tlocalhost → %v\n", strings.Join(append(hops, transport.BastionHostAlias), " → "))
+						}
 					}
 
 					return nil
 				},
-				Flags: nil,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "tag",
+						Usage: "Only list the configs with this tag (may be repeated, configs must have every tag)",
+					},
+				},
 			},
 			{
 				Name:      "connect",
 				Usage:     "Connect to a drawbridge managed ssh config",
-				ArgsUsage: "[config_number/alias] [dest_server_hostname]",
+				ArgsUsage: "[config_number/id/alias/selector] [dest_server_hostname]",
 				Action: func(c *cli.Context) error {
 					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
@@ -204,11 +325,11 @@ OPTIONS:
 					}
 
 					config.SetOptionsFromAnswers(answerData)
-					connectAction := actions.ConnectAction{Config: config}
+					connectAction := actions.ConnectAction{Config: config, PrintCommand: c.Bool("print-command"), ShellExport: c.Bool("shell-export"), Record: c.Bool("record")}
 					return connectAction.Start(answerData, destServer, c.Bool("debug"))
 				},
 
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.StringFlag{
 						Name:  "dest",
 						Usage: "Specify the `hostname` of the destination/internal server you would like to connect to.",
@@ -218,12 +339,16 @@ OPTIONS:
 						Value: false,
 						Usage: "Debug mode",
 					},
-				},
+					&cli.BoolFlag{
+						Name:  "record",
+						Usage: "Record the session to an asciicast file in the config_dir/recordings directory (uses the native transport)",
+					},
+				}, commandFlags()...),
 			},
 			{
 				Name:      "alias",
-				Usage:     "Create a named alias for a drawbridge config",
-				ArgsUsage: "[config_number] [alias]",
+				Usage:     "Manage the named aliases of drawbridge configs",
+				ArgsUsage: "[config_number/id/alias/selector] [alias]",
 				Action: func(c *cli.Context) error {
 					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
@@ -231,16 +356,44 @@ OPTIONS:
 					if err != nil {
 						return err
 					}
+					aliasAction := actions.AliasAction{Config: config}
+
+					if c.IsSet("remove") {
+						if c.NArg() > 0 {
+							return errors.InvalidArgumentsError(fmt.Sprintf("0 arguments allowed with --remove. %v provided", c.NArg()))
+						}
+						return aliasAction.Remove(projectList, c.String("remove"))
+					} else if c.IsSet("rename") {
+						if c.NArg() != 1 {
+							return errors.InvalidArgumentsError(fmt.Sprintf("1 argument (the new alias) required with --rename. %v provided", c.NArg()))
+						}
+						if err := project.ValidateAlias(c.Args().Get(0)); err != nil {
+							return err
+						}
+						return aliasAction.Rename(projectList, c.String("rename"), c.Args().Get(0))
+					}
+
+					// `alias --add alias [config]` or `alias [config] [alias]`
+					configArg := c.Args().Get(0)
+					configAlias := c.Args().Get(1)
+					if c.IsSet("add") {
+						if c.NArg() > 1 {
+							return errors.InvalidArgumentsError(fmt.Sprintf("at most 1 argument (the config) allowed with --add. %v provided", c.NArg()))
+						}
+						configArg = c.Args().Get(0)
+						configAlias = c.String("add")
+						if err := project.ValidateAlias(configAlias); err != nil {
+							return err
+						}
+					}
 
 					var answerData map[string]interface{}
 					var answerIndex int
-					if c.NArg() > 0 {
-
-						answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+					if len(configArg) > 0 {
+						answerData, answerIndex, err = projectList.GetWithAliasOrIndex(configArg)
 						if err != nil {
 							return err
 						}
-
 					} else {
 						answerData, answerIndex, err = projectList.Prompt("Enter drawbridge config number to create alias for")
 						if err != nil {
@@ -253,35 +406,206 @@ OPTIONS:
 						fmt.Printf("\t%v: %v\n", color.YellowString(k), v)
 					}
 
-					//get the alias name (if provided)
-					var configAlias string
-					if c.NArg() >= 2 {
-						configAlias = c.Args().Get(1)
-						isValid, err := regexp.MatchString(`^[A-Za-z][\w-\.]+$`, configAlias)
-						if err != nil || !isValid {
-							configAlias = utils.StdinQueryRegex("Please provide an alias for the configuration above", `^[A-Za-z][\w-\.]+$`, "a-zA-Z0-9-_.")
+					//get the alias name (if not provided, or invalid)
+					if project.ValidateAlias(configAlias) != nil {
+						configAlias, err = utils.StdinQueryRegex("Please provide an alias for the configuration above", project.AliasPattern, "a-zA-Z0-9-_.")
+						if err != nil {
+							return err
+						}
+					}
+
+					return aliasAction.Add(projectList, answerIndex, configAlias)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "add",
+						Usage: "Add an alias to the config (prompts for the config if it is not provided)",
+					},
+					&cli.StringFlag{
+						Name:  "remove",
+						Usage: "Remove an alias",
+					},
+					&cli.StringFlag{
+						Name:  "rename",
+						Usage: "Rename an alias, eg. `drawbridge alias --rename old-alias new-alias`",
+					},
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List the aliases of drawbridge configs",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							aliasAction := actions.AliasAction{Config: config}
+							return aliasAction.List(c.String("output"))
+						},
+					},
+				},
+			},
+			{
+				Name:      "tag",
+				Usage:     "Add (or remove) tags on a drawbridge config, eg. primary or deprecated",
+				ArgsUsage: "[config_number/id/alias/selector] [tag...]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					var answerIndexes []int
+					if c.NArg() > 0 {
+						_, answerIndexes, err = projectList.GetAllWithAliasOrIndex(c.Args().Get(0))
+						if err != nil {
+							return err
 						}
 					} else {
-						configAlias = utils.StdinQueryRegex("Please provide an alias for the configuration above", `^[A-Za-z][\w-\.]+$`, "a-zA-Z0-9-_.")
+						_, answerIndex, err := projectList.Prompt("Enter drawbridge config number to tag")
+						if err != nil {
+							return err
+						}
+						answerIndexes = []int{answerIndex}
 					}
 
-					_, _, aliasErr := projectList.GetWithAlias(configAlias)
-					if aliasErr == nil {
-						return errors.ConfigValidationError("alias already exists.")
+					tags := c.Args().Tail()
+					if len(tags) == 0 && c.Bool("remove") {
+						return errors.InvalidArgumentsError("at least 1 tag is required with --remove")
+					} else if len(tags) == 0 {
+						tag, err := utils.StdinQueryRegex("Please provide a tag for the configuration above", project.TagPattern, "a-zA-Z0-9-_.")
+						if err != nil {
+							return err
+						}
+						tags = []string{tag}
 					}
 
-					color.HiBlue("Setting alias (%s) for config (%d)\n", configAlias, answerIndex+1)
+					for _, answerIndex := range answerIndexes {
+						if c.Bool("remove") {
+							color.HiBlue("Removing tags (%s) from config (%d)\n", strings.Join(tags, ", "), answerIndex+1)
+							_, err = projectList.RemoveTagsForIndex(answerIndex, tags)
+						} else {
+							color.HiBlue("Adding tags (%s) to config (%d)\n", strings.Join(tags, ", "), answerIndex+1)
+							_, err = projectList.AddTagsForIndex(answerIndex, tags)
+						}
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "remove",
+						Usage: "Remove the tags instead of adding them",
+					},
+				},
+			},
+			{
+				Name:      "note",
+				Usage:     "Set a free-form note on a drawbridge config, eg. \"use for kafka only\"",
+				ArgsUsage: "[config_number/id/alias/selector] [note]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
-					_, err = projectList.SetAliasForIndex(answerIndex, configAlias)
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
 
+					var answerIndex int
+					if c.NArg() > 0 {
+						_, answerIndex, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+					} else {
+						_, answerIndex, err = projectList.Prompt("Enter drawbridge config number to set a note for")
+					}
+					if err != nil {
+						return err
+					}
+
+					if c.Bool("clear") {
+						color.HiBlue("Clearing note for config (%d)\n", answerIndex+1)
+						_, err = projectList.SetNotesForIndex(answerIndex, "")
+						return err
+					}
+
+					// the note may be quoted, or passed as multiple arguments
+					notes := strings.Join(c.Args().Tail(), " ")
+					if len(strings.TrimSpace(notes)) == 0 {
+						notes, err = utils.StdinQuery("Please provide a note for the configuration above:")
+						if err != nil {
+							return err
+						}
+					}
+
+					color.HiBlue("Setting note for config (%d)\n", answerIndex+1)
+					_, err = projectList.SetNotesForIndex(answerIndex, notes)
 					return err
 				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "clear",
+						Usage: "Remove the note from the config",
+					},
+				},
+			},
+			{
+				Name:      "exec",
+				Usage:     "Run a command on one or more internal servers using drawbridge managed ssh config",
+				ArgsUsage: "[config_number/id/alias/selector] -- command [args...]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					// everything after `--` is the remote command. The flag parser consumes `--` when no config is provided.
+					args := c.Args().Slice()
+					configArgs := []string{}
+					commandArgs := args
+					for ndx, arg := range args {
+						if arg == "--" {
+							configArgs = args[:ndx]
+							commandArgs = args[ndx+1:]
+							break
+						}
+					}
+					if len(configArgs) > 1 {
+						return errors.InvalidArgumentsError("only one config number/alias may be specified before `--`, flags (eg. --hosts) must come before the config number/alias")
+					}
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					var answerData map[string]interface{}
+					if len(configArgs) == 1 {
+						answerData, _, err = projectList.GetWithAliasOrIndex(configArgs[0])
+					} else {
+						answerData, _, err = projectList.Prompt("Enter drawbridge config number to run command through")
+					}
+					if err != nil {
+						return err
+					}
+
+					execAction := actions.ExecAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+					return execAction.Start(answerData, c.StringSlice("hosts"), commandArgs, c.Int("parallel"), c.String("output"))
+				},
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "hosts",
+						Usage: "Comma separated `hostnames` of the destination/internal servers to run the command on",
+					},
+					&cli.IntFlag{
+						Name:  "parallel",
+						Usage: "Maximum number of hosts to run the command on at the same time (0 for all)",
+						Value: 10,
+					},
+				},
 			},
 			{
 				Name:      "download",
 				Aliases:   []string{"scp"},
 				Usage:     "Download a file from an internal server using drawbridge managed ssh config, syntax is similar to scp command. ",
-				ArgsUsage: "[config_number/alias] destination_hostname:remote_filepath local_filepath",
+				ArgsUsage: "[config_number/id/alias/selector] destination_hostname:remote_filepath local_filepath",
 				Action: func(c *cli.Context) error {
 					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
@@ -331,14 +655,448 @@ OPTIONS:
 					}
 
 					config.SetOptionsFromAnswers(answerData)
-					downloadAction := actions.DownloadAction{Config: config}
+					downloadAction := actions.DownloadAction{
+						ConnectAction: actions.ConnectAction{Config: config, PrintCommand: c.Bool("print-command"), ShellExport: c.Bool("shell-export")},
+						Config:        config,
+					}
 					return downloadAction.Start(answerData, strRemoteHostname, strRemotePath, strLocalPath)
 				},
+				Flags: commandFlags(),
+			},
+			{
+				Name:      "upload",
+				Usage:     "Upload a file to an internal server using drawbridge managed ssh config, syntax is similar to scp command. ",
+				ArgsUsage: "[config_number/id/alias/selector] local_filepath destination_hostname:remote_filepath",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					// PARSE ARGS
+					if c.NArg() < 2 || c.NArg() > 3 {
+						return errors.InvalidArgumentsError(fmt.Sprintf("2 or 3 arguments required. %v provided", c.Args().Len()))
+					}
+
+					strRemoteHostname := ""
+					strRemotePath := ""
+					strLocalPath := ""
+
+					args := c.Args().Slice()
+
+					var answerData map[string]interface{}
+
+					if c.NArg() == 3 {
+						answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						args = c.Args().Tail()
+					}
+
+					strLocalPath = args[0]
+
+					remoteParts := strings.Split(args[1], ":")
+					if len(remoteParts) != 2 {
+						return errors.InvalidArgumentsError(fmt.Sprintf("Invalid `destination_hostname:remote path` format: %s", remoteParts))
+					} else {
+						strRemoteHostname = remoteParts[0]
+						strRemotePath = remoteParts[1]
+					}
+
+					//config was not specified, lets prompt for the answerData
+					if answerData == nil {
+						answerData, _, err = projectList.Prompt("Enter number of drawbridge config you would like to upload to")
+						if err != nil {
+							return err
+						}
+					}
+
+					config.SetOptionsFromAnswers(answerData)
+					uploadAction := actions.UploadAction{
+						ConnectAction: actions.ConnectAction{Config: config, PrintCommand: c.Bool("print-command"), ShellExport: c.Bool("shell-export")},
+						Config:        config,
+					}
+					return uploadAction.Start(answerData, strLocalPath, strRemoteHostname, strRemotePath, c.Bool("recursive"))
+				},
+
+				Flags: append([]cli.Flag{
+					&cli.BoolFlag{
+						Name:    "recursive",
+						Aliases: []string{"r"},
+						Usage:   "Recursively copy entire directories (enabled automatically when local_filepath is a directory)",
+					},
+				}, commandFlags()...),
+			},
+			{
+				Name:      "ping",
+				Usage:     "Check that the bastions of drawbridge managed ssh configs are reachable (all configs if none are specified)",
+				ArgsUsage: "[config_number/id/alias/selector...]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					answerDataList := projectList.GetAll()
+					if c.NArg() > 0 {
+						answerDataList = []map[string]interface{}{}
+						for _, aliasOrIndex := range c.Args().Slice() {
+							selectedAnswerDataList, _, err := projectList.GetAllWithAliasOrIndex(aliasOrIndex)
+							if err != nil {
+								return err
+							}
+							answerDataList = append(answerDataList, selectedAnswerDataList...)
+						}
+					}
+
+					pingAction := actions.PingAction{Config: config}
+					return pingAction.Start(answerDataList, c.Duration("timeout"), c.String("output"))
+				},
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Timeout for each stage (DNS, TCP & SSH banner) of the check",
+						Value: transport.DefaultPreflightTimeout,
+					},
+				},
+			},
+			{
+				Name:  "history",
+				Usage: "Show the audit log of connect, download & upload sessions",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					filter := audit.Filter{Alias: c.String("alias"), Host: c.String("host")}
+					if c.IsSet("since") {
+						since, err := audit.ParseTime(c.String("since"), time.Now(), false)
+						if err != nil {
+							return err
+						}
+						filter.Since = since
+					}
+					if c.IsSet("until") {
+						until, err := audit.ParseTime(c.String("until"), time.Now(), true)
+						if err != nil {
+							return err
+						}
+						filter.Until = until
+					}
+
+					historyAction := actions.HistoryAction{Config: config}
+					return historyAction.List(filter, c.Int("limit"), c.String("output"))
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "alias",
+						Usage: "Only show sessions for the config with this alias (any of its aliases) or config ID",
+					},
+					&cli.StringFlag{
+						Name:  "host",
+						Usage: "Only show sessions to this destination host (`bastion` for the bastion itself)",
+					},
+					&cli.StringFlag{
+						Name:  "since",
+						Usage: "Only show sessions after this date (2006-01-02), RFC3339 timestamp or duration ago (24h)",
+					},
+					&cli.StringFlag{
+						Name:  "until",
+						Usage: "Only show sessions before this date (inclusive), RFC3339 timestamp or duration ago (24h)",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "Only show the most recent sessions, 0 for no limit",
+						Value: 0,
+					},
+				},
+			},
+			{
+				Name:      "replay",
+				Usage:     "Play back a session recorded with `connect --record`, or list the recordings",
+				ArgsUsage: "[recording_number/alias/recording_filepath]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					replayAction := actions.ReplayAction{Config: config}
+					if c.NArg() == 0 {
+						return replayAction.List(c.String("output"))
+					}
+					return replayAction.Start(c.Args().Get(0), c.Float64("speed"), c.Duration("idle-limit"))
+				},
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:  "speed",
+						Usage: "Playback speed multiplier",
+						Value: 1,
+					},
+					&cli.DurationFlag{
+						Name:  "idle-limit",
+						Usage: "Shorten pauses longer than this duration, 0 to keep the original timing",
+						Value: 2 * time.Second,
+					},
+				},
+			},
+			{
+				Name:      "tunnel",
+				Usage:     "Keep the port forwards of drawbridge managed ssh configs up in the background",
+				ArgsUsage: "[config_number/id/alias/selector...]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					answerDataList := []map[string]interface{}{}
+					if c.Bool("all") {
+						answerDataList = projectList.GetAll()
+					} else if c.NArg() > 0 {
+						for _, aliasOrIndex := range c.Args().Slice() {
+							selectedAnswerDataList, _, err := projectList.GetAllWithAliasOrIndex(aliasOrIndex)
+							if err != nil {
+								return err
+							}
+							answerDataList = append(answerDataList, selectedAnswerDataList...)
+						}
+					} else {
+						answerData, _, err := projectList.Prompt("Enter drawbridge config number to open a tunnel for")
+						if err != nil {
+							return err
+						}
+						answerDataList = append(answerDataList, answerData)
+					}
+
+					tunnelAction := actions.TunnelAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+					return tunnelAction.Start(answerDataList)
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Open tunnels for all configuration files",
+					},
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "status",
+						Usage: "Show the status of running drawbridge tunnels",
+						Action: func(c *cli.Context) error {
+							tunnelAction := actions.TunnelAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+							return tunnelAction.PrintStatus(c.String("output"))
+						},
+					},
+					{
+						Name:      "stop",
+						Usage:     "Stop running drawbridge tunnels (all tunnels if no config is specified)",
+						ArgsUsage: "[config_number/id/alias/selector...]",
+						Action: func(c *cli.Context) error {
+							answerDataList := []map[string]interface{}{}
+							if c.NArg() > 0 {
+								projectList, err := project.CreateProjectListFromConfigDir(config)
+								if err != nil {
+									return err
+								}
+								for _, aliasOrIndex := range c.Args().Slice() {
+									selectedAnswerDataList, _, err := projectList.GetAllWithAliasOrIndex(aliasOrIndex)
+									if err != nil {
+										return err
+									}
+									answerDataList = append(answerDataList, selectedAnswerDataList...)
+								}
+							}
+
+							tunnelAction := actions.TunnelAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+							return tunnelAction.Stop(answerDataList)
+						},
+					},
+					{
+						Name:      "run",
+						Usage:     "Run a tunnel in the foreground (used internally by `drawbridge tunnel`)",
+						ArgsUsage: "config_filepath",
+						Hidden:    true,
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return errors.InvalidArgumentsError(fmt.Sprintf("1 argument required. %v provided", c.Args().Len()))
+							}
+
+							tunnelAction := actions.TunnelAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+							return tunnelAction.Run(c.Args().Get(0), c.String("id"), c.String("alias"))
+						},
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "id",
+								Usage: "ID of the config, names the tunnel state & log files (derived from the config filepath by default)",
+							},
+							&cli.StringFlag{
+								Name:  "alias",
+								Usage: "Alias of the config, displayed by `tunnel status`",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "key",
+				Usage: "Manage the PEM keys used by drawbridge managed ssh configs",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List the PEM keys referenced by drawbridge managed ssh configs",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							keyAction := actions.KeyAction{Config: config}
+							return keyAction.List(c.String("output"))
+						},
+					},
+					{
+						Name:      "import",
+						Usage:     "Copy a PEM key into the pem_dir location used by a drawbridge config, storing its passphrase in the secret store",
+						ArgsUsage: "[config_number/id/alias/selector] pem_filepath",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							if c.NArg() < 1 || c.NArg() > 2 {
+								return errors.InvalidArgumentsError(fmt.Sprintf("1 or 2 arguments required. %v provided", c.Args().Len()))
+							}
+
+							projectList, err := project.CreateProjectListFromConfigDir(config)
+							if err != nil {
+								return err
+							}
+
+							var answerData map[string]interface{}
+							if c.NArg() == 2 {
+								answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+							} else {
+								answerData, _, err = projectList.Prompt("Enter drawbridge config number to import the PEM key for")
+							}
+							if err != nil {
+								return err
+							}
+
+							keyAction := actions.KeyAction{Config: config}
+							return keyAction.Import(answerData, c.Args().Get(c.NArg()-1), actions.KeyImportOptions{
+								SecretStore:    c.Bool("secret-store"),
+								PassphraseOnly: c.Bool("passphrase-only"),
+								Force:          c.Bool("force"),
+							})
+						},
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "secret-store",
+								Usage: "Store the PEM key in the secret store, instead of copying it into the pem_dir",
+							},
+							&cli.BoolFlag{
+								Name:  "passphrase-only",
+								Usage: "Only store the passphrase of the encrypted PEM key, the key itself is not copied",
+							},
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "Overwrite a different PEM key that already exists in the pem_dir",
+							},
+						},
+					},
+					{
+						Name:      "generate",
+						Usage:     "Generate a new ed25519 keypair at the pem_dir location used by a drawbridge config",
+						ArgsUsage: "[config_number/id/alias/selector]",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							projectList, err := project.CreateProjectListFromConfigDir(config)
+							if err != nil {
+								return err
+							}
+
+							var answerData map[string]interface{}
+							if c.NArg() > 0 {
+								answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+							} else {
+								answerData, _, err = projectList.Prompt("Enter drawbridge config number to generate a PEM key for")
+							}
+							if err != nil {
+								return err
+							}
+
+							keyAction := actions.KeyAction{Config: config}
+							return keyAction.Generate(answerData, c.Bool("force"))
+						},
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "Overwrite the PEM key if it already exists",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "agent",
+				Usage: "Manage the PEM keys that drawbridge added to the ssh-agent",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List the PEM keys that drawbridge added to the ssh-agent",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							agentAction := actions.AgentAction{Config: config}
+							return agentAction.List(c.String("output"))
+						},
+					},
+					{
+						Name:      "remove",
+						Usage:     "Remove the PEM keys that drawbridge added to the ssh-agent",
+						ArgsUsage: "[config_number/id/alias/selector...]",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							agentAction := actions.AgentAction{Config: config}
+							if c.Bool("all") {
+								return agentAction.Remove([]string{})
+							} else if c.NArg() == 0 {
+								return errors.InvalidArgumentsError("at least 1 config_number/alias (or --all) is required")
+							}
+
+							projectList, err := project.CreateProjectListFromConfigDir(config)
+							if err != nil {
+								return err
+							}
+
+							pemFilepaths := []string{}
+							for _, arg := range c.Args().Slice() {
+								answerDataList, _, err := projectList.GetAllWithAliasOrIndex(arg)
+								if err != nil {
+									return err
+								}
+								for _, answerData := range answerDataList {
+									pemFilepath, err := agentAction.PemFilepath(answerData)
+									if err != nil {
+										return err
+									}
+									pemFilepaths = append(pemFilepaths, pemFilepath)
+								}
+							}
+							return agentAction.Remove(pemFilepaths)
+						},
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Remove every PEM key that drawbridge added to the ssh-agent",
+							},
+						},
+					},
+				},
 			},
 			{
 				Name:      "delete",
 				Usage:     "Delete drawbridge managed ssh config(s)",
-				ArgsUsage: "[config_number/alias]",
+				ArgsUsage: "[config_number/id/alias/selector]",
 				Action: func(c *cli.Context) error {
 					fmt.Fprintln(c.App.Writer, c.Command.Usage)
 
@@ -355,12 +1113,17 @@ OPTIONS:
 						return deleteAction.All(projectList.GetAll(), c.Bool("force"))
 
 					} else if c.NArg() > 0 {
-						//check if the user specified a config number in the args.
+						//check if the user specified a config number, alias or selector in the args.
 
-						answerData, _, err = projectList.GetWithAliasOrIndex(c.Args().Get(0))
+						answerDataList, _, err := projectList.GetAllWithAliasOrIndex(c.Args().Get(0))
 						if err != nil {
 							return err
+						} else if len(answerDataList) > 1 {
+							//delete every config matching the selector, each is confirmed unless forced (non-interactive mode requires --force).
+							deleteAction := actions.DeleteAction{Config: config}
+							return deleteAction.All(answerDataList, c.Bool("force"))
 						}
+						answerData = answerDataList[0]
 
 					} else {
 						// prompt the user to determine which configs to delete.
@@ -397,6 +1160,62 @@ OPTIONS:
 					//TODO: add dry run support
 				},
 			},
+			{
+				Name:    "check",
+				Aliases: []string{"doctor"},
+				Usage:   "Detect drawbridge managed ssh configs that are stale, invalid or orphaned with the current drawbridge.yaml",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					checkAction := actions.CheckAction{Config: config}
+					return checkAction.Start(c.String("output"))
+				},
+			},
+			{
+				Name:      "regenerate",
+				Usage:     "Re-render drawbridge managed ssh configs & custom templates after the templates in drawbridge.yaml change",
+				ArgsUsage: "[config_number/id/alias/selector...]",
+				Action: func(c *cli.Context) error {
+					fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+					projectList, err := project.CreateProjectListFromConfigDir(config)
+					if err != nil {
+						return err
+					}
+
+					answerDataList := []map[string]interface{}{}
+					if c.Bool("all") {
+						answerDataList = projectList.GetAll()
+					} else if c.NArg() > 0 {
+						for _, aliasOrIndex := range c.Args().Slice() {
+							selectedAnswerDataList, _, err := projectList.GetAllWithAliasOrIndex(aliasOrIndex)
+							if err != nil {
+								return err
+							}
+							answerDataList = append(answerDataList, selectedAnswerDataList...)
+						}
+					} else {
+						answerData, _, err := projectList.Prompt("Enter drawbridge config number to regenerate")
+						if err != nil {
+							return err
+						}
+						answerDataList = append(answerDataList, answerData)
+					}
+
+					regenerateAction := actions.RegenerateAction{Config: config}
+					return regenerateAction.Start(answerDataList, c.Bool("dryrun"))
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Regenerate all configuration files",
+					},
+					&cli.BoolFlag{
+						Name:  "dryrun",
+						Usage: "Dry Run mode. Will print the changes rather than writing them to disk.",
+					},
+				},
+			},
 			{
 				Name:  "proxy",
 				Usage: "Build/Rebuild a Proxy auto-config (PAC) file to access websites through Drawbridge tunnels",
@@ -409,9 +1228,34 @@ OPTIONS:
 					}
 					answerDataList := projectList.GetAll()
 
-					proxyAction := actions.ProxyAction{Config: config}
+					proxyAction := actions.ProxyAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
 					return proxyAction.Start(answerDataList, false)
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "serve",
+						Usage: "Run a local SOCKS5/HTTP proxy that routes requests through the matching Drawbridge config, and serves the PAC file over HTTP",
+						Action: func(c *cli.Context) error {
+							fmt.Fprintln(c.App.Writer, c.Command.Usage)
+
+							projectList, err := project.CreateProjectListFromConfigDir(config)
+							if err != nil {
+								return err
+							}
+							answerDataList := projectList.GetAll()
+
+							proxyAction := actions.ProxyAction{ConnectAction: actions.ConnectAction{Config: config}, Config: config}
+							return proxyAction.Serve(answerDataList, c.String("listen"))
+						},
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "listen",
+								Value: "localhost:8118",
+								Usage: "`address` the proxy server listens on",
+							},
+						},
+					},
+				},
 			},
 			{
 				Name:  "update",
@@ -425,19 +1269,41 @@ OPTIONS:
 					}
 
 					updateAction := actions.UpdateAction{Config: config}
-					return updateAction.Start()
+					return updateAction.Start(c.Bool("force"))
+				},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Force update with no confirmation",
+					},
 				},
 			},
 		},
 	}
 
 	err = app.Run(os.Args)
+	actions.StopPrivateAgent()
 	if err != nil {
-		log.Fatal(color.HiRedString("ERROR: %v", err))
+		log.Error(color.HiRedString("ERROR: %v", err))
+		os.Exit(errors.ExitCode(err))
 	}
 
 }
 
+// flags shared by the commands that hand off to ssh/scp
+func commandFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "print-command",
+			Usage: "Print the fully-resolved ssh/scp command line (config path, host & ssh-agent socket) before running it",
+		},
+		&cli.BoolFlag{
+			Name:  "shell-export",
+			Usage: "Print the ssh/scp command line as a shell snippet, instead of running it",
+		},
+	}
+}
+
 func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 	flags := []cli.Flag{
 		&cli.StringFlag{
@@ -450,6 +1316,15 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 			Usage:       "Activated custom_templates",
 			DefaultText: strings.Join(appConfig.GetStringSlice("options.active_custom_templates"), ", "),
 		},
+		&cli.IntFlag{
+			Name:        "agent_key_lifetime",
+			Usage:       "Lifetime (in seconds) of the PEM key in the ssh-agent for this config, 0 for no limit",
+			DefaultText: appConfig.GetString("options.agent_key_lifetime"),
+		},
+		&cli.BoolFlag{
+			Name:  "agent_confirm",
+			Usage: "Require confirmation before each use of the PEM key from the ssh-agent for this config",
+		},
 		&cli.BoolFlag{
 			Name:  "dryrun",
 			Usage: "Dry Run mode. Will print files and paths to STDOUT rather than writing them to disk.",
@@ -462,6 +1337,17 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 		},
 	}
 
+	questionFlags, err := questionFlags(appConfig, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(flags, questionFlags...), nil
+}
+
+// a flag for each question in the config. `edit` flags have no default values, since only changed answers are set.
+func questionFlags(appConfig config.Interface, withDefaults bool) ([]cli.Flag, error) {
+	flags := []cli.Flag{}
+
 	configQuestions, err := appConfig.GetQuestions()
 	if err != nil {
 		return nil, err
@@ -475,7 +1361,7 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 				Usage: v.Description,
 			}
 			defaultValue, ok := v.DefaultValue.(string)
-			if ok {
+			if ok && withDefaults {
 				newFlag.Value = defaultValue
 			}
 
@@ -486,7 +1372,7 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 				Usage: v.Description,
 			}
 			defaultValue, ok := v.DefaultValue.(int)
-			if ok {
+			if ok && withDefaults {
 				newFlag.Value = defaultValue
 			}
 
@@ -497,7 +1383,7 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 				Usage: v.Description,
 			}
 			defaultValue, ok := v.DefaultValue.(bool)
-			if ok {
+			if ok && withDefaults {
 				newFlag.Value = defaultValue
 			}
 
@@ -507,6 +1393,35 @@ func createFlags(appConfig config.Interface) ([]cli.Flag, error) {
 	return flags, nil
 }
 
+// returns the typed answers for the question flags set on the command line.
+func questionFlagHandler(appConfig config.Interface, cliFlags []string, c *cli.Context) (map[string]interface{}, error) {
+	cliAnswers := map[string]interface{}{}
+	for _, flagName := range cliFlags {
+		//skip dryrun & debug
+		if flagName == "dryrun" || flagName == "debug" {
+			continue
+		}
+
+		question, err := appConfig.GetQuestion(flagName)
+		if err != nil {
+			return nil, err
+		}
+
+		questionType := question.GetType()
+
+		if questionType == "string" {
+			cliAnswers[flagName] = c.String(flagName)
+
+		} else if questionType == "integer" {
+			cliAnswers[flagName] = c.Int(flagName)
+
+		} else if questionType == "boolean" {
+			cliAnswers[flagName] = c.Bool(flagName)
+		}
+	}
+	return cliAnswers, nil
+}
+
 func createFlagHandler(appConfig config.Interface, answerValues map[string]interface{}, cliFlags []string, c *cli.Context) (map[string]interface{}, error) {
 	//there's 4 special cases we need to handle for "defaultOptions":
 	//case 1: no flag override and no answer option
@@ -544,6 +1459,16 @@ func createFlagHandler(appConfig config.Interface, answerValues map[string]inter
 
 	for _, flagName := range cliFlags {
 
+		if utils.SliceIncludes(actions.AgentOptionKeys, flagName) {
+			//agent options are stored in the answers file, so they only apply to this config.
+			if flagName == "agent_confirm" {
+				cliAnswers[flagName] = c.Bool(flagName)
+			} else {
+				cliAnswers[flagName] = c.Int(flagName)
+			}
+			continue
+		}
+
 		if utils.SliceIncludes(optionKeys, flagName) {
 			//this flag is actually an "option". Lets set it.
 			log.Debugf("\nSetting option from CLI: %v (%v)", flagName, c.String(flagName))
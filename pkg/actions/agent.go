@@ -0,0 +1,160 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// every key added to the ssh-agent by drawbridge has a comment starting with this prefix, followed by the pem_filepath
+const agentKeyCommentPrefix = "(drawbridge)"
+
+// AgentOptionKeys are the options that can be overridden for a single config, by passing them as flags to
+// `drawbridge create`. Unlike other options, they are only stored in the answers file when overridden.
+var AgentOptionKeys = []string{"agent_key_lifetime", "agent_confirm"}
+
+// AgentAction shows & removes the keys that drawbridge added to the ssh-agent
+type AgentAction struct {
+	Config config.Interface
+}
+
+// AgentKey is a key loaded in the ssh-agent by drawbridge
+type AgentKey struct {
+	PemFilePath string `json:"pem_filepath" yaml:"pem_filepath"`
+	Type        string `json:"type" yaml:"type"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	Comment     string `json:"comment" yaml:"comment"`
+}
+
+// List prints the keys that drawbridge added to the ssh-agent.
+func (e *AgentAction) List(outputFormat string) error {
+	agentKeys, err := e.Keys()
+	if err != nil {
+		return err
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, agentKeys)
+	}
+
+	if len(agentKeys) == 0 {
+		fmt.Println("No drawbridge keys are loaded in the ssh-agent")
+		return nil
+	}
+	for _, agentKey := range agentKeys {
+		fmt.Printf("%v %v %v\n", color.GreenString(agentKey.PemFilePath), agentKey.Type, agentKey.Fingerprint)
+	}
+	return nil
+}
+
+// Keys returns the keys in the ssh-agent with a `(drawbridge)` comment
+func (e *AgentAction) Keys() ([]AgentKey, error) {
+	agentClient, agentConn, err := sshAgentClient()
+	if err != nil {
+		return nil, err
+	}
+	defer agentConn.Close()
+
+	keys, err := agentClient.List()
+	if err != nil {
+		return nil, err
+	}
+
+	agentKeys := []AgentKey{}
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Comment, agentKeyCommentPrefix) {
+			continue
+		}
+		agentKeys = append(agentKeys, AgentKey{
+			PemFilePath: strings.TrimPrefix(key.Comment, agentKeyCommentPrefix+" - "),
+			Type:        key.Type(),
+			Fingerprint: ssh.FingerprintSHA256(key),
+			Comment:     key.Comment,
+		})
+	}
+	return agentKeys, nil
+}
+
+// Remove drops the drawbridge keys for the provided pem filepaths from the ssh-agent, or every drawbridge key if no
+// pem filepaths are provided. Keys added outside of drawbridge are never removed.
+func (e *AgentAction) Remove(pemFilepaths []string) error {
+	agentClient, agentConn, err := sshAgentClient()
+	if err != nil {
+		return err
+	}
+	defer agentConn.Close()
+
+	keys, err := agentClient.List()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Comment, agentKeyCommentPrefix) {
+			continue
+		}
+		pemFilepath := strings.TrimPrefix(key.Comment, agentKeyCommentPrefix+" - ")
+		if len(pemFilepaths) > 0 && !utils.SliceIncludes(pemFilepaths, pemFilepath) {
+			continue
+		}
+		if err := agentClient.Remove(key); err != nil {
+			return err
+		}
+		color.Green("Removed %v (%v) from the ssh-agent", pemFilepath, ssh.FingerprintSHA256(key))
+		removed++
+	}
+
+	if removed == 0 {
+		fmt.Println("No matching drawbridge keys are loaded in the ssh-agent")
+	}
+	return nil
+}
+
+// PemFilepath returns the pem_filepath of a drawbridge config, as it appears in the comment of its ssh-agent key.
+func (e *AgentAction) PemFilepath(answerData map[string]interface{}) (string, error) {
+	_, pemFilepath, err := renderedFilepaths(e.Config, answerData)
+	if err != nil {
+		return "", err
+	} else if len(pemFilepath) == 0 {
+		return "", errors.ConfigValidationError("the active config template does not define a pem_filepath")
+	}
+	return pemFilepath, nil
+}
+
+// connects to the ssh-agent listening on SSH_AUTH_SOCK. The returned connection must be closed by the caller.
+func sshAgentClient() (agent.ExtendedAgent, io.Closer, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if len(socket) == 0 {
+		return nil, nil, errors.DependencyMissingError("ssh-agent is not running (SSH_AUTH_SOCK is not set)")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, err
+	}
+	return agent.NewClient(conn), conn, nil
+}
+
+// checks if a key with the same fingerprint is already loaded in the ssh-agent
+func agentHasKey(agentClient agent.Agent, publicKey ssh.PublicKey) (bool, error) {
+	keys, err := agentClient.List()
+	if err != nil {
+		return false, err
+	}
+	fingerprint := ssh.FingerprintSHA256(publicKey)
+	for _, key := range keys {
+		if ssh.FingerprintSHA256(key) == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
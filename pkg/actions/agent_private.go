@@ -0,0 +1,204 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// set for `drawbridge tunnel run` processes when `tunnel start` will add a PEM key to their private ssh-agent.
+const privateAgentKeyEnvVar = "DRAWBRIDGE_PRIVATE_AGENT_KEY"
+
+// privateAgent is the in-process ssh-agent that drawbridge serves when no ssh-agent is running (SSH_AUTH_SOCK is not
+// set). It only lives as long as the drawbridge process, so ssh/scp must run as child processes while it is in use.
+var privateAgent struct {
+	sync.Mutex
+	listener   net.Listener
+	keyring    agent.Agent
+	socketPath string
+
+	// the keys added to the private agent, by pem_filepath, so they can be handed to `drawbridge tunnel run` processes.
+	keys map[string]agent.AddedKey
+}
+
+// startPrivateAgent serves an in-memory ssh-agent keyring on `<config_dir>/agent/<pid>.sock`, and exports the socket
+// via SSH_AUTH_SOCK to the ssh/scp processes started by drawbridge.
+func startPrivateAgent(appConfig config.Interface) (string, error) {
+	privateAgent.Lock()
+	defer privateAgent.Unlock()
+	if privateAgent.listener != nil {
+		return privateAgent.socketPath, nil
+	}
+
+	agentDir, err := privateAgentDir(appConfig)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(agentDir, 0700); err != nil {
+		return "", err
+	}
+	removeStalePrivateAgentSockets(agentDir)
+
+	socketPath := filepath.Join(agentDir, fmt.Sprintf("%v.sock", os.Getpid()))
+	os.Remove(socketPath)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return "", err
+	}
+	// the socket grants access to the loaded keys, only the current user may connect.
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return "", err
+	}
+
+	keyring := agent.NewKeyring()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if err := agent.ServeAgent(keyring, conn); err != nil {
+					log.Debugf("private ssh-agent connection closed: %v", err)
+				}
+			}()
+		}
+	}()
+
+	privateAgent.listener = listener
+	privateAgent.keyring = keyring
+	privateAgent.socketPath = socketPath
+	privateAgent.keys = map[string]agent.AddedKey{}
+	os.Setenv("SSH_AUTH_SOCK", socketPath)
+	log.Debugf("Started private ssh-agent on %v", socketPath)
+	return socketPath, nil
+}
+
+// StopPrivateAgent shuts down the private ssh-agent (if it was started) and removes its socket.
+func StopPrivateAgent() {
+	privateAgent.Lock()
+	defer privateAgent.Unlock()
+	if privateAgent.listener == nil {
+		return
+	}
+	privateAgent.listener.Close()
+	os.Remove(privateAgent.socketPath)
+	if os.Getenv("SSH_AUTH_SOCK") == privateAgent.socketPath {
+		os.Unsetenv("SSH_AUTH_SOCK")
+	}
+	privateAgent.listener = nil
+	privateAgent.keyring = nil
+	privateAgent.socketPath = ""
+	privateAgent.keys = nil
+}
+
+func privateAgentRunning() bool {
+	privateAgent.Lock()
+	defer privateAgent.Unlock()
+	return privateAgent.listener != nil
+}
+
+// records a key added to the private agent, so it can be forwarded to a tunnel process later.
+func rememberPrivateAgentKey(pemFilepath string, addedKey agent.AddedKey) {
+	privateAgent.Lock()
+	defer privateAgent.Unlock()
+	if privateAgent.keys != nil {
+		privateAgent.keys[pemFilepath] = addedKey
+	}
+}
+
+// forwardPrivateAgentKey adds the key for pemFilepath to the private agent of another drawbridge process (see
+// `tunnel run`), waiting for its socket to be created.
+func forwardPrivateAgentKey(appConfig config.Interface, pid int, pemFilepath string, timeout time.Duration) error {
+	privateAgent.Lock()
+	addedKey, found := privateAgent.keys[pemFilepath]
+	privateAgent.Unlock()
+	if !found {
+		return nil
+	}
+
+	agentDir, err := privateAgentDir(appConfig)
+	if err != nil {
+		return err
+	}
+	socketPath := filepath.Join(agentDir, fmt.Sprintf("%v.sock", pid))
+
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.Dial("unix", socketPath)
+		if err == nil {
+			defer conn.Close()
+			return agent.NewClient(conn).Add(addedKey)
+		} else if time.Now().After(deadline) || !processAlive(pid) {
+			return fmt.Errorf("the private ssh-agent of process %v is not available: %v", pid, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// waitForPrivateAgentKeys blocks until a key is added to the private agent, or the timeout expires.
+func waitForPrivateAgentKeys(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		privateAgent.Lock()
+		keyring := privateAgent.keyring
+		privateAgent.Unlock()
+		if keyring == nil {
+			return false
+		}
+		if keys, err := keyring.List(); err == nil && len(keys) > 0 {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+// environment for processes that outlive this one, they cannot use the private agent.
+func environWithoutPrivateAgent() []string {
+	environ := os.Environ()
+	if !privateAgentRunning() {
+		return environ
+	}
+	filtered := []string{}
+	for _, env := range environ {
+		if !strings.HasPrefix(env, "SSH_AUTH_SOCK=") {
+			filtered = append(filtered, env)
+		}
+	}
+	return filtered
+}
+
+func privateAgentDir(appConfig config.Interface) (string, error) {
+	return utils.ExpandPath(filepath.Join(appConfig.GetString("options.config_dir"), "agent"))
+}
+
+// sockets are left behind when drawbridge is killed, remove the ones belonging to processes that no longer exist.
+func removeStalePrivateAgentSockets(agentDir string) {
+	files, err := ioutil.ReadDir(agentDir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		pid, err := strconv.Atoi(strings.TrimSuffix(file.Name(), ".sock"))
+		if err != nil || !strings.HasSuffix(file.Name(), ".sock") {
+			continue
+		}
+		if !processAlive(pid) {
+			os.Remove(filepath.Join(agentDir, file.Name()))
+		}
+	}
+}
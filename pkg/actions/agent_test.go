@@ -0,0 +1,165 @@
+package actions_test
+
+import (
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh/agent"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serves an in-memory keyring on a temporary SSH_AUTH_SOCK
+func serveTestAgent(t *testing.T, keyring agent.Agent) func() {
+	socketDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	socketPath := filepath.Join(socketDir, "agent.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+
+	previousSocket := os.Getenv("SSH_AUTH_SOCK")
+	os.Setenv("SSH_AUTH_SOCK", socketPath)
+	return func() {
+		os.Setenv("SSH_AUTH_SOCK", previousSocket)
+		listener.Close()
+		os.RemoveAll(socketDir)
+	}
+}
+
+func TestConnectAction_SshAgentAddPemKey_AlreadyLoaded(t *testing.T) {
+	//setup
+	keyring := agent.NewKeyring()
+	defer serveTestAgent(t, keyring)()
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.secret_store", "none")
+	connectAction := actions.ConnectAction{Config: configData}
+	pemFilepath := filepath.Join("testdata", "connect", "test_rsa.pem")
+
+	//test
+	err = connectAction.SshAgentAddPemKey(pemFilepath, map[string]interface{}{})
+	require.NoError(t, err)
+	err = connectAction.SshAgentAddPemKey(pemFilepath, map[string]interface{}{"agent_key_lifetime": 600})
+
+	//assert
+	require.NoError(t, err)
+	keys, err := keyring.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1, "should not add a key that is already loaded in the ssh-agent")
+	require.Equal(t, "(drawbridge) - "+pemFilepath, keys[0].Comment)
+}
+
+func TestAgentAction_Keys_Remove(t *testing.T) {
+	//setup
+	keyring := agent.NewKeyring()
+	defer serveTestAgent(t, keyring)()
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.secret_store", "none")
+	connectAction := actions.ConnectAction{Config: configData}
+	agentAction := actions.AgentAction{Config: configData}
+	pemFilepath := filepath.Join("testdata", "connect", "test_rsa.pem")
+	require.NoError(t, connectAction.SshAgentAddPemKey(pemFilepath, map[string]interface{}{}))
+
+	//test
+	agentKeys, err := agentAction.Keys()
+	require.NoError(t, err)
+	unmatchedErr := agentAction.Remove([]string{"testdata/missing.pem"})
+	unmatchedKeys, err := keyring.List()
+	require.NoError(t, err)
+	removeErr := agentAction.Remove([]string{})
+
+	//assert
+	require.Len(t, agentKeys, 1)
+	require.Equal(t, pemFilepath, agentKeys[0].PemFilePath)
+	require.Equal(t, "ssh-rsa", agentKeys[0].Type)
+	require.NoError(t, unmatchedErr)
+	require.Len(t, unmatchedKeys, 1, "should only remove the keys for the provided pem filepaths")
+	require.NoError(t, removeErr)
+	keys, err := keyring.List()
+	require.NoError(t, err)
+	require.Empty(t, keys, "should remove every drawbridge key")
+}
+
+func TestConnectAction_SshAgentAddPemKey_PrivateAgent(t *testing.T) {
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	previousSocket := os.Getenv("SSH_AUTH_SOCK")
+	os.Unsetenv("SSH_AUTH_SOCK")
+	defer os.Setenv("SSH_AUTH_SOCK", previousSocket)
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.secret_store", "none")
+	connectAction := actions.ConnectAction{Config: configData}
+	agentAction := actions.AgentAction{Config: configData}
+	pemFilepath := filepath.Join("testdata", "connect", "test_rsa.pem")
+
+	//test
+	err = connectAction.SshAgentAddPemKey(pemFilepath, map[string]interface{}{})
+	socketPath := os.Getenv("SSH_AUTH_SOCK")
+	agentKeys, keysErr := agentAction.Keys()
+	actions.StopPrivateAgent()
+
+	//assert
+	require.NoError(t, err, "should start a private ssh-agent when SSH_AUTH_SOCK is not set")
+	require.Equal(t, filepath.Join(parentPath, "agent", fmt.Sprintf("%v.sock", os.Getpid())), socketPath)
+	require.NoError(t, keysErr)
+	require.Len(t, agentKeys, 1)
+	require.Equal(t, pemFilepath, agentKeys[0].PemFilePath)
+	require.NoFileExists(t, socketPath, "should remove the socket when the private ssh-agent is stopped")
+	require.Empty(t, os.Getenv("SSH_AUTH_SOCK"))
+}
+
+func TestConnectAction_SshAgentAddPemKey_SecretStoreUnavailable(t *testing.T) {
+	//setup
+	keyring := agent.NewKeyring()
+	defer serveTestAgent(t, keyring)()
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	secretsFilepath := filepath.Join(parentPath, "secrets", "drawbridge.enc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(secretsFilepath), 0700))
+	require.NoError(t, ioutil.WriteFile(secretsFilepath, []byte("not: [an encrypted file"), 0600))
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.secret_store", "file")
+	connectAction := actions.ConnectAction{Config: configData}
+	brokenConfigData, err := config.Create()
+	require.NoError(t, err)
+	brokenConfigData.Set("options.secret_store", "unknown")
+	brokenConnectAction := actions.ConnectAction{Config: brokenConfigData}
+
+	//test
+	missingErr := connectAction.SshAgentAddPemKey(filepath.Join(parentPath, "missing.pem"), map[string]interface{}{})
+	err = brokenConnectAction.SshAgentAddPemKey(filepath.Join("testdata", "connect", "test_rsa.pem"), map[string]interface{}{})
+
+	//assert
+	require.IsType(t, errors.PemKeyMissingError(""), missingErr, "should fall back to the pem_dir when the secret store cannot be read")
+	require.NoError(t, err, "should not open the secret store for an unencrypted key in the pem_dir")
+	keys, err := keyring.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+type AliasAction struct {
+	Config config.Interface
+}
+
+// List prints every alias, and the config it refers to.
+func (e *AliasAction) List(outputFormat string) error {
+	projectList, err := project.CreateProjectListFromConfigDir(e.Config)
+	if err != nil {
+		return err
+	}
+	aliasEntries := projectList.GetAllAliases()
+
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, aliasEntries)
+	}
+
+	if len(aliasEntries) == 0 {
+		color.Yellow("No aliases found, create one with `drawbridge alias [config_number] [alias]`")
+		return nil
+	}
+	for _, aliasEntry := range aliasEntries {
+		fmt.Printf("%v -> %v %v\n", color.GreenString(aliasEntry.Alias), color.YellowString("[%v, %v]", aliasEntry.Index, aliasEntry.ID), aliasEntry.ConfigFilePath)
+	}
+	return nil
+}
+
+// Add adds an alias to the config at the (0-based) index, then updates the ssh config index so the alias can be used
+// as a Host.
+func (e *AliasAction) Add(projectList project.ProjectList, index_0based int, alias string) error {
+	color.HiBlue("Adding alias (%s) for config (%d)\n", alias, index_0based+1)
+	if _, err := projectList.AddAliasForIndex(index_0based, alias); err != nil {
+		return err
+	}
+	return e.updateIndex()
+}
+
+// Remove removes the alias from the config that has it.
+func (e *AliasAction) Remove(projectList project.ProjectList, alias string) error {
+	color.HiBlue("Removing alias (%s)\n", alias)
+	if _, err := projectList.RemoveAlias(alias); err != nil {
+		return err
+	}
+	return e.updateIndex()
+}
+
+// Rename replaces the alias with newAlias.
+func (e *AliasAction) Rename(projectList project.ProjectList, alias string, newAlias string) error {
+	color.HiBlue("Renaming alias (%s) to (%s)\n", alias, newAlias)
+	if _, err := projectList.RenameAlias(alias, newAlias); err != nil {
+		return err
+	}
+	return e.updateIndex()
+}
+
+func (e *AliasAction) updateIndex() error {
+	indexAction := IndexAction{Config: e.Config}
+	return indexAction.Start(false)
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// records the outcome of a connect/download/upload in the audit log (see `options.audit_log`). Nothing is recorded in
+// shell-export mode, since no command is run.
+func (e *ConnectAction) audit(entry audit.Entry, answerData map[string]interface{}, err error) {
+	if len(entry.Outcome) == 0 {
+		entry.Outcome = audit.OutcomeSuccess
+		if err != nil {
+			entry.Outcome = audit.OutcomeFailure
+			entry.Error = err.Error()
+		}
+	}
+	if e.Config == nil || e.ShellExport {
+		return
+	}
+
+	auditLogPath, pathErr := audit.FilePath(e.Config)
+	if pathErr != nil || len(auditLogPath) == 0 {
+		return
+	}
+
+	entry.ConfigFilepath, _, _ = renderedFilepaths(e.Config, answerData)
+	entry.ID = project.ID(answerData)
+	entry.Aliases = project.Aliases(answerData)
+	if len(entry.Host) == 0 {
+		entry.Host = "bastion"
+	}
+	entry.Answers = map[string]interface{}{}
+	for answerKey, answerValue := range answerData {
+		if !utils.SliceIncludes(e.Config.InternalQuestionKeys(), answerKey) {
+			entry.Answers[answerKey] = answerValue
+		}
+	}
+
+	// the session has already happened (or failed), so a broken audit log only warrants a warning.
+	if auditErr := audit.Append(auditLogPath, entry); auditErr != nil {
+		color.Yellow("WARNING: Could not write to the audit log %v: %v", auditLogPath, auditErr)
+	}
+}
+
+// records that the drawbridge process is about to be replaced by ssh/scp, so the outcome will not be known.
+func (e *ConnectAction) auditStarted(entry audit.Entry, answerData map[string]interface{}) {
+	entry.Outcome = audit.OutcomeStarted
+	e.audit(entry, answerData, nil)
+}
@@ -0,0 +1,272 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+const (
+	// the stored answers would render different files with the current drawbridge.yaml
+	CheckIssueStale = "stale"
+	// the stored answers no longer pass the current question schema, or cannot be rendered at all
+	CheckIssueInvalid = "invalid"
+	// a file in the config_dir is not referenced by any answers file
+	CheckIssueOrphaned = "orphaned"
+)
+
+type CheckAction struct {
+	Config config.Interface
+}
+
+type CheckIssue struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	FilePath string `json:"filepath" yaml:"filepath"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+// CheckResult contains the issues detected for a single answers file in the config_dir
+type CheckResult struct {
+	AnswerFilePath string       `json:"answers_filepath" yaml:"answers_filepath"`
+	ConfigFilePath string       `json:"config_filepath" yaml:"config_filepath"`
+	Issues         []CheckIssue `json:"issues" yaml:"issues"`
+}
+
+// CheckReport is printed by `drawbridge check --output json|yaml`
+type CheckReport struct {
+	Results []CheckResult `json:"results" yaml:"results"`
+	Orphans []CheckIssue  `json:"orphans" yaml:"orphans"`
+}
+
+// Start checks every answers file in the config_dir against the current drawbridge.yaml, prints a report and returns
+// an error if any stale, invalid or orphaned configs were found.
+func (e *CheckAction) Start(outputFormat string) error {
+	results, orphans, err := e.Check()
+	if err != nil {
+		return err
+	}
+
+	issueCount := len(orphans)
+	for _, result := range results {
+		issueCount += len(result.Issues)
+	}
+	checkFailedErr := errors.CheckFailedError(fmt.Sprintf("found %v issue(s) in %v", issueCount, e.Config.GetString("options.config_dir")))
+
+	if utils.IsStructuredOutput(outputFormat) {
+		err = utils.PrintStructured(os.Stdout, outputFormat, CheckReport{Results: results, Orphans: orphans})
+		if err == nil && issueCount > 0 {
+			return checkFailedErr
+		}
+		return err
+	}
+
+	for _, result := range results {
+		if len(result.Issues) == 0 {
+			color.Green("✔ %v", result.ConfigFilePath)
+			continue
+		}
+		color.Red("✘ %v", result.ConfigFilePath)
+		for _, issue := range result.Issues {
+			printCheckIssue(issue)
+		}
+	}
+
+	if len(orphans) > 0 {
+		color.Red("✘ %v", e.Config.GetString("options.config_dir"))
+		for _, issue := range orphans {
+			printCheckIssue(issue)
+		}
+	}
+
+	if issueCount > 0 {
+		fmt.Println("\nRun `drawbridge regenerate` to re-render stale configs.")
+		return checkFailedErr
+	}
+	color.Green("\nAll %v drawbridge configs are up to date", len(results))
+	return nil
+}
+
+// Check re-renders the templates for each answers file in memory, compares them with the files on disk and validates
+// the stored answers. Files in the config_dir that are not managed by any answers file are returned as orphans.
+func (e *CheckAction) Check() ([]CheckResult, []CheckIssue, error) {
+	configDir, err := utils.ExpandPath(e.Config.GetString("options.config_dir"))
+	if err != nil {
+		return nil, nil, err
+	}
+	answerFiles, err := project.AnswerFilesInConfigDir(configDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := []CheckResult{}
+	managedFiles := map[string]bool{}
+	for _, answerFilePath := range answerFiles {
+		managedFiles[answerFilePath] = true
+
+		result := CheckResult{AnswerFilePath: answerFilePath, ConfigFilePath: answerFilePath, Issues: []CheckIssue{}}
+		answerProjectData, err := project.CreateProjectFromConfigDirAnswerFile(answerFilePath)
+		if err != nil {
+			result.Issues = append(result.Issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: answerFilePath, Message: err.Error()})
+			results = append(results, result)
+			continue
+		}
+		result.ConfigFilePath = answerProjectData.ConfigFilePath
+		for _, managedFilePath := range storedFilePaths(answerProjectData.Answers) {
+			managedFiles[managedFilePath] = true
+		}
+
+		result.Issues = append(result.Issues, e.validateAnswers(answerFilePath, answerProjectData.Answers)...)
+		result.Issues = append(result.Issues, e.compareRendered(answerFilePath, answerProjectData.Answers)...)
+		results = append(results, result)
+	}
+
+	indexAction := IndexAction{Config: e.Config}
+	indexFilePath, err := indexAction.FilePath()
+	if err != nil {
+		return nil, nil, err
+	} else if len(indexFilePath) > 0 {
+		managedFiles[indexFilePath] = true
+	}
+
+	auditLogPath, err := audit.FilePath(e.Config)
+	if err != nil {
+		return nil, nil, err
+	} else if len(auditLogPath) > 0 {
+		managedFiles[auditLogPath] = true
+	}
+
+	orphans, err := orphanedFiles(configDir, managedFiles)
+	return results, orphans, err
+}
+
+// validates each stored answer against the current question schema
+func (e *CheckAction) validateAnswers(answerFilePath string, answerData map[string]interface{}) []CheckIssue {
+	issues := []CheckIssue{}
+
+	questions, err := e.Config.GetQuestions()
+	if err != nil {
+		return append(issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: answerFilePath, Message: err.Error()})
+	}
+
+	questionKeys := []string{}
+	for questionKey := range questions {
+		questionKeys = append(questionKeys, questionKey)
+	}
+	sort.Strings(questionKeys)
+
+	for _, questionKey := range questionKeys {
+		question := questions[questionKey]
+		answerValue, answered := answerData[questionKey]
+		if !answered {
+			if question.Required() {
+				issues = append(issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: answerFilePath, Message: fmt.Sprintf("missing answer for required question %v", questionKey)})
+			}
+			continue
+		}
+		if err := question.Validate(questionKey, answerValue); err != nil {
+			issues = append(issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: answerFilePath, Message: fmt.Sprintf("%v: %v", questionKey, err)})
+		}
+	}
+
+	answerKeys := []string{}
+	for answerKey := range answerData {
+		if _, isQuestion := questions[answerKey]; !isQuestion && !utils.SliceIncludes(e.Config.InternalQuestionKeys(), answerKey) {
+			answerKeys = append(answerKeys, answerKey)
+		}
+	}
+	sort.Strings(answerKeys)
+	for _, answerKey := range answerKeys {
+		issues = append(issues, CheckIssue{Kind: CheckIssueStale, FilePath: answerFilePath, Message: fmt.Sprintf("answer for %v no longer matches a question in drawbridge.yaml", answerKey)})
+	}
+	return issues
+}
+
+// re-renders the templates in memory and compares them with the files on disk
+func (e *CheckAction) compareRendered(answerFilePath string, answerData map[string]interface{}) []CheckIssue {
+	issues := []CheckIssue{}
+
+	regenerateAction := RegenerateAction{Config: e.Config}
+	rendered, err := regenerateAction.Render(answerData)
+	if err != nil {
+		return append(issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: answerFilePath, Message: fmt.Sprintf("templates can no longer be rendered: %v", err)})
+	}
+
+	for _, renderedFile := range rendered.Files {
+		if len(renderedFile.PreviousFilePath) > 0 {
+			issues = append(issues, CheckIssue{Kind: CheckIssueStale, FilePath: renderedFile.PreviousFilePath, Message: fmt.Sprintf("would be moved to %v", renderedFile.FilePath)})
+			continue
+		}
+		if !utils.FileExists(renderedFile.FilePath) {
+			issues = append(issues, CheckIssue{Kind: CheckIssueStale, FilePath: renderedFile.FilePath, Message: "file is missing"})
+			continue
+		}
+		content, err := ioutil.ReadFile(renderedFile.FilePath)
+		if err != nil {
+			issues = append(issues, CheckIssue{Kind: CheckIssueInvalid, FilePath: renderedFile.FilePath, Message: err.Error()})
+			continue
+		}
+		if string(content) != renderedFile.Content {
+			issues = append(issues, CheckIssue{Kind: CheckIssueStale, FilePath: renderedFile.FilePath, Message: "content differs from the current template"})
+		}
+	}
+	return issues
+}
+
+// returns the config, pem & custom template filepaths recorded in an answers file
+func storedFilePaths(answerData map[string]interface{}) []string {
+	filePaths := []string{}
+	if configData, ok := answerData["config"].(map[string]interface{}); ok {
+		for _, key := range []string{"filepath", "pem_filepath"} {
+			if filePath, ok := configData[key].(string); ok && len(filePath) > 0 {
+				filePaths = append(filePaths, filePath)
+			}
+		}
+	}
+	if customDataList, ok := answerData["custom"].([]interface{}); ok {
+		for _, customData := range customDataList {
+			if customDataMap, ok := customData.(map[string]interface{}); ok {
+				if filePath, ok := customDataMap["filepath"].(string); ok && len(filePath) > 0 {
+					filePaths = append(filePaths, filePath)
+				}
+			}
+		}
+	}
+	return filePaths
+}
+
+// returns the regular files at the top level of the config_dir that are not referenced by any answers file.
+// sub directories (eg. tunnels) are managed by other commands and are skipped.
+func orphanedFiles(configDir string, managedFiles map[string]bool) ([]CheckIssue, error) {
+	orphans := []CheckIssue{}
+
+	entries, err := ioutil.ReadDir(configDir)
+	if os.IsNotExist(err) {
+		return orphans, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filePath := filepath.Join(configDir, entry.Name())
+		if !managedFiles[filePath] {
+			orphans = append(orphans, CheckIssue{Kind: CheckIssueOrphaned, FilePath: filePath, Message: "not managed by any drawbridge answers file"})
+		}
+	}
+	return orphans, nil
+}
+
+func printCheckIssue(issue CheckIssue) {
+	fmt.Printf("    %v %v: %v\n", color.YellowString("[%v]", issue.Kind), issue.FilePath, issue.Message)
+}
@@ -0,0 +1,111 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckAction_Check(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	err = createAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, false)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "audit.log"), []byte{}, 0600))
+	checkAction := actions.CheckAction{Config: configData}
+
+	//test
+	results, orphans, err := checkAction.Check()
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, 1, len(results))
+	require.Empty(t, results[0].Issues, "should not report issues for a freshly created config")
+	require.Empty(t, orphans, "should not report the audit log as orphaned")
+}
+
+func TestCheckAction_Check_WithDrift(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	err = createAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, false)
+	require.NoError(t, err)
+
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n  Port 2222\n",
+		},
+	})
+	questions, err := configData.GetQuestions()
+	require.NoError(t, err)
+	questions["environment"].Schema["enum"] = []string{"stage", "prod"}
+	configData.Set("questions", questions)
+	err = ioutil.WriteFile(filepath.Join(parentPath, "orphaned-config"), []byte("Host bastion\n"), 0644)
+	require.NoError(t, err)
+	checkAction := actions.CheckAction{Config: configData}
+
+	//test
+	results, orphans, err := checkAction.Check()
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, 1, len(results))
+	require.Equal(t, 2, len(results[0].Issues))
+	require.Equal(t, actions.CheckIssueInvalid, results[0].Issues[0].Kind, "should detect that the environment answer is no longer valid")
+	require.Equal(t, actions.CheckIssueStale, results[0].Issues[1].Kind, "should detect that the template content changed")
+	require.Equal(t, 1, len(orphans))
+	require.Equal(t, filepath.Join(parentPath, "orphaned-config"), orphans[0].FilePath)
+	require.Error(t, checkAction.Start(utils.OutputFormatText), "should return an error when issues are found")
+}
@@ -0,0 +1,57 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// loads the PEM key into the ssh-agent. In shell-export mode drawbridge exits without running anything, so a private
+// ssh-agent would be useless: the key is only loaded when an ssh-agent is already running.
+func (e *ConnectAction) loadPemKey(pemFilepath string, answerData map[string]interface{}) error {
+	if e.ShellExport && len(os.Getenv("SSH_AUTH_SOCK")) == 0 {
+		return nil
+	}
+	return e.SshAgentAddPemKey(pemFilepath, answerData)
+}
+
+// prints the command line (including the ssh-agent socket) when `--print-command` is set. The native transport does not
+// run ssh/scp, the equivalent command is printed instead.
+func (e *ConnectAction) printCommand(args []string, native bool) {
+	if !e.PrintCommand {
+		return
+	}
+	commandLine := utils.ShellQuote(args...)
+	if socket := os.Getenv("SSH_AUTH_SOCK"); len(socket) > 0 {
+		commandLine = "SSH_AUTH_SOCK=" + utils.ShellQuote(socket) + " " + commandLine
+	}
+	if native {
+		color.Cyan("Equivalent command: %v", commandLine)
+	} else {
+		color.Cyan("Running: %v", commandLine)
+	}
+}
+
+// prints the command line as a shell snippet for `--shell-export`, without running it.
+func (e *ConnectAction) exportCommand(args []string, pemFilepath string) error {
+	fmt.Print(shellExport(args, pemFilepath, os.Getenv("SSH_AUTH_SOCK")))
+	return nil
+}
+
+func shellExport(args []string, pemFilepath string, agentSocket string) string {
+	lines := []string{}
+	if len(agentSocket) > 0 {
+		lines = append(lines, "export SSH_AUTH_SOCK="+utils.ShellQuote(agentSocket))
+	} else if len(pemFilepath) > 0 {
+		lines = append(lines,
+			"# no ssh-agent is running, start one & load the PEM key first",
+			`eval "$(ssh-agent -s)"`,
+			"ssh-add "+utils.ShellQuote(pemFilepath),
+		)
+	}
+	lines = append(lines, utils.ShellQuote(args...))
+	return strings.Join(lines, "\n") + "\n"
+}
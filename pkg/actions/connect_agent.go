@@ -0,0 +1,205 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/secrets"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// SshAgentAddPemKey registers the PEM key with the ssh-agent, unless a key with the same fingerprint is already loaded.
+// If the key is missing from the pem_dir, its contents are loaded from the secret store (see `drawbridge key import`).
+// Passphrases for encrypted keys are read from the secret store before prompting.
+// The key lifetime & confirm-before-use constraints come from the answers (if set with `drawbridge create`) or options.
+func (e *ConnectAction) SshAgentAddPemKey(pemFilepath string, answerData map[string]interface{}) error {
+	// the secret store is only opened when the key is missing from the pem_dir or encrypted, since the file store
+	// prompts for its password.
+	var secretStore secrets.Store
+	openSecretStore := func() (secrets.Store, error) {
+		if secretStore == nil {
+			var err error
+			if secretStore, err = e.secretStore(); err != nil {
+				return nil, err
+			}
+		}
+		return secretStore, nil
+	}
+
+	//first lets ensure that the pem key exists (on disk or in the secret store) and read the pem file data
+	keyData, err := readPemKey(pemFilepath, openSecretStore)
+	if err != nil {
+		return err
+	}
+
+	//ensure that an ssh-agent is available, starting a private one if necessary.
+	if err := e.ensureSshAgent(); err != nil {
+		return err
+	}
+
+	agentClient, agentConn, err := sshAgentClient()
+	if err != nil {
+		return err
+	}
+	defer agentConn.Close()
+
+	// check if this pemfile is already added to the ssh-agent, before prompting for a passphrase (if possible)
+	if publicKey, _, err := pemPublicKey(pemFilepath, keyData); err == nil && publicKey != nil {
+		if loaded, err := agentHasKey(agentClient, publicKey); err != nil {
+			return err
+		} else if loaded {
+			fmt.Printf("PEM key (%v) is already loaded in the ssh-agent\n", pemFilepath)
+			return nil
+		}
+	}
+
+	//https://github.com/golang/crypto/blob/master/ssh/keys.go
+
+	//decode the ssh pem key (and handle encypted/passphrase protected keys)
+	privateKeyData, err := ssh.ParseRawPrivateKey(keyData)
+	if _, encrypted := err.(*ssh.PassphraseMissingError); encrypted {
+		privateKeyData, err = parseEncryptedPemKey(pemFilepath, keyData, openSecretStore)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the public key of encrypted keys may only be available after decrypting them.
+	signer, err := ssh.NewSignerFromKey(privateKeyData)
+	if err != nil {
+		return err
+	}
+	if loaded, err := agentHasKey(agentClient, signer.PublicKey()); err != nil {
+		return err
+	} else if loaded {
+		fmt.Printf("PEM key (%v) is already loaded in the ssh-agent\n", pemFilepath)
+		return nil
+	}
+
+	// register the privatekey with ssh-agent
+	fmt.Printf("Adding PEM key (%v) to ssh-agent\n", pemFilepath)
+	lifetimeSecs, confirmBeforeUse := e.agentKeyConstraints(answerData)
+	addedKey := agent.AddedKey{
+		PrivateKey:       privateKeyData,
+		Comment:          fmt.Sprintf("%v - %v", agentKeyCommentPrefix, pemFilepath),
+		LifetimeSecs:     lifetimeSecs, //for safety we should limit this key's use (1h by default)
+		ConfirmBeforeUse: confirmBeforeUse,
+	}
+	if err := agentClient.Add(addedKey); err != nil {
+		return err
+	}
+	if privateAgentRunning() {
+		rememberPrivateAgentKey(pemFilepath, addedKey)
+	}
+	return nil
+}
+
+// when no ssh-agent is running (SSH_AUTH_SOCK is not set), drawbridge serves its own in-process agent.
+func (e *ConnectAction) ensureSshAgent() error {
+	if len(os.Getenv("SSH_AUTH_SOCK")) > 0 {
+		return nil
+	}
+	if e.Config == nil {
+		return errors.DependencyMissingError("ssh-agent is not running (SSH_AUTH_SOCK is not set)")
+	}
+	socketPath, err := startPrivateAgent(e.Config)
+	if err != nil {
+		return errors.DependencyMissingError(fmt.Sprintf("ssh-agent is not running, and a private ssh-agent could not be started: %v", err))
+	}
+	fmt.Printf("No ssh-agent is running, using a private drawbridge ssh-agent (%v)\n", socketPath)
+	return nil
+}
+
+// returns the key lifetime (0 means no limit) & whether the agent should confirm each use of the key. The options can
+// be overridden for a single config by passing them as flags to `drawbridge create`.
+func (e *ConnectAction) agentKeyConstraints(answerData map[string]interface{}) (uint32, bool) {
+	lifetimeSecs := 3600
+	confirmBeforeUse := false
+	if e.Config != nil {
+		lifetimeSecs = e.Config.GetInt("options.agent_key_lifetime")
+		confirmBeforeUse = e.Config.GetBool("options.agent_confirm")
+	}
+
+	if answerLifetime, ok := answerData["agent_key_lifetime"]; ok && answerLifetime != nil {
+		if lifetime, err := strconv.Atoi(fmt.Sprintf("%v", answerLifetime)); err == nil {
+			lifetimeSecs = lifetime
+		}
+	}
+	if answerConfirm, ok := answerData["agent_confirm"]; ok && answerConfirm != nil {
+		if confirm, err := strconv.ParseBool(fmt.Sprintf("%v", answerConfirm)); err == nil {
+			confirmBeforeUse = confirm
+		}
+	}
+
+	if lifetimeSecs < 0 {
+		lifetimeSecs = 0
+	}
+	return uint32(lifetimeSecs), confirmBeforeUse
+}
+
+// returns the configured secret store, or nil if the action was created without a config.
+func (e *ConnectAction) secretStore() (secrets.Store, error) {
+	if e.Config == nil {
+		return nil, nil
+	}
+	return secrets.New(e.Config)
+}
+
+// reads the PEM key from the pem_dir, falling back to the contents imported into the secret store. Secret store errors
+// are only reported as warnings.
+func readPemKey(pemFilepath string, openSecretStore func() (secrets.Store, error)) ([]byte, error) {
+	if utils.FileExists(pemFilepath) {
+		return ioutil.ReadFile(pemFilepath)
+	}
+
+	if keyData, found := secretStoreGet(openSecretStore, secrets.PemKey(pemFilepath)); found {
+		log.Debugf("Loaded PEM key (%v) from the secret store", pemFilepath)
+		return []byte(keyData), nil
+	}
+	return nil, errors.PemKeyMissingError(fmt.Sprintf("No pem file exists at %v", pemFilepath))
+}
+
+// decrypts the PEM key with the passphrase from the secret store, prompting the user if it is missing or incorrect.
+//https://stackoverflow.com/questions/42105432/how-to-use-an-encrypted-private-key-with-golang-ssh
+func parseEncryptedPemKey(pemFilepath string, keyData []byte, openSecretStore func() (secrets.Store, error)) (interface{}, error) {
+	if passphrase, found := secretStoreGet(openSecretStore, secrets.PassphraseKey(pemFilepath)); found {
+		privateKeyData, err := ssh.ParseRawPrivateKeyWithPassphrase(keyData, []byte(passphrase))
+		if err == nil {
+			return privateKeyData, nil
+		}
+		color.Yellow("WARNING: The passphrase stored for %v is incorrect: %v", pemFilepath, err)
+	}
+
+	//inform the user that the key is encrypted.
+	passphrase, err := utils.StdinQueryPassword(fmt.Sprintf("The key at %v is encrypted and requires a passphrase. Please enter it below:", pemFilepath))
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParseRawPrivateKeyWithPassphrase(keyData, []byte(passphrase))
+}
+
+// returns the secret stored for the key. An unavailable secret store is not fatal, the caller falls back to the pem_dir
+// or prompting, so errors are printed as warnings and reported as not found.
+func secretStoreGet(openSecretStore func() (secrets.Store, error), key string) (string, bool) {
+	secretStore, err := openSecretStore()
+	if err != nil {
+		color.Yellow("WARNING: Could not open the secret store: %v", err)
+		return "", false
+	} else if secretStore == nil {
+		return "", false
+	}
+
+	secret, found, err := secretStore.Get(key)
+	if err != nil {
+		color.Yellow("WARNING: Could not read from the %v: %v", secretStore.Name(), err)
+		return "", false
+	}
+	return secret, found
+}
@@ -14,7 +14,7 @@ import (
 //	connectAction := actions.ConnectAction{}
 //
 //	//test
-//	err := connectAction.SshAgentAddPemKey(path.Join("testdata", "connect/test_rsa.pem"))
+//	err := connectAction.SshAgentAddPemKey(path.Join("testdata", "connect/test_rsa.pem"), map[string]interface{}{})
 //
 //	//assert
 //	require.NoError(t, err, "should not raise an error when adding pem key to ssh-agent")
@@ -27,7 +27,7 @@ func TestConnectAction_SshAgentAddPemKey_InvalidPath(t *testing.T) {
 	connectAction := actions.ConnectAction{}
 
 	//test
-	err := connectAction.SshAgentAddPemKey(filepath.Join("testdata", "invalid_path.pem"))
+	err := connectAction.SshAgentAddPemKey(filepath.Join("testdata", "invalid_path.pem"), map[string]interface{}{})
 
 	//assert
 	require.Error(t, err, "should raise an error when adding invalid pem key to ssh-agent")
@@ -3,17 +3,13 @@
 package actions
 
 import (
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
+	"github.com/analogj/drawbridge/pkg/audit"
 	"github.com/analogj/drawbridge/pkg/config"
 	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/transport"
 	"github.com/analogj/drawbridge/pkg/utils"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/crypto/ssh"
-	"golang.org/x/crypto/ssh/agent"
-	"io/ioutil"
-	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -21,121 +17,93 @@ import (
 
 type ConnectAction struct {
 	Config config.Interface
+
+	// PrintCommand prints the fully-resolved ssh/scp command line before running it
+	PrintCommand bool
+	// ShellExport prints the ssh/scp command line as a shell snippet, instead of running it
+	ShellExport bool
+	// Record saves the output of the interactive session to an asciicast file (connect only)
+	Record bool
 }
 
-func (e *ConnectAction) Start(answerData map[string]interface{}, destHostname string, debugMode bool) error {
+func (e *ConnectAction) Start(answerData map[string]interface{}, destHostname string, debugMode bool) (err error) {
 	log.Debugf("Answer Data: %v", answerData)
 
+	auditEntry := audit.Entry{Action: "connect", Host: destHostname}
+	defer func() { e.audit(auditEntry, answerData, err) }()
+
 	tmplData, err := e.Config.GetActiveConfigTemplate()
 	if err != nil {
-		return nil
+		return err
 	}
 
 	tmplConfigFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.config_dir"), tmplData.FilePath), answerData)
 	if err != nil {
-		return nil
+		return err
+	}
+
+	if e.Config.GetBool("options.bastion_preflight") && !e.ShellExport {
+		if err := e.BastionPreflight(tmplConfigFilepath); err != nil {
+			return err
+		}
 	}
 
+	pemFilepath := ""
 	if tmplData.PemFilePath != "" {
 		tmplPemFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.pem_dir"), tmplData.PemFilePath), answerData)
 		if err != nil {
-			return nil
+			return err
 		}
+		pemFilepath = tmplPemFilepath
 
-		//TODO: Print the lines we're running.
-
-		//TODO: Check that the bastion host is accessible.
-
-		err = e.SshAgentAddPemKey(tmplPemFilepath)
+		err = e.loadPemKey(tmplPemFilepath, answerData)
 		if err != nil {
 			return err
 		}
 	}
 
-	//https://gobyexample.com/execing-processes
-	//https://groob.io/posts/golang-execve/
-
-	fmt.Println("Opening ssh tunnel")
-	_, lookErr := exec.LookPath("ssh")
-	if lookErr != nil {
-		return errors.DependencyMissingError("ssh is missing")
-	}
-
 	configHost := "bastion"
 	if len(destHostname) > 0 {
 		configHost = fmt.Sprintf("%v.in", destHostname)
 	}
-	args := []string{"ssh", configHost, "-F", tmplConfigFilepath}
 
+	args := []string{"ssh", configHost, "-F", tmplConfigFilepath}
 	if debugMode {
 		fmt.Printf("Debug mode enabled")
 		args = append(args, "-vvv")
 	}
-
-	// windows does not support exec -- simulate exec by running the command with the I/O wired to the parent process
-	cmd := exec.Command(args[0], args[1:len(args)]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stdin = os.Stdin
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
-func (e *ConnectAction) SshAgentAddPemKey(pemFilepath string) error {
-	//first lets ensure that the pemFilepath exists
-	if !utils.FileExists(pemFilepath) {
-		return errors.PemKeyMissingError(fmt.Sprintf("No pem file exists at %v", pemFilepath))
-	}
-
-	//ensure that the ssh-agent is available on this machine.
-	_, err := exec.LookPath("ssh-agent")
-	if err != nil {
-		return errors.DependencyMissingError("ssh-agent is missing")
-	}
-
-	//read the pem file data
-	keyData, err := ioutil.ReadFile(pemFilepath)
-	if err != nil {
-		return err
+	if e.ShellExport {
+		return e.exportCommand(args, pemFilepath)
 	}
 
-	//TODO: check if this pemfile is already added to the ssh-agent
-
-	//decode the ssh pem key (and handle encypted/passphrase protected keys)
-	//https://stackoverflow.com/questions/42105432/how-to-use-an-encrypted-private-key-with-golang-ssh
-	block, _ := pem.Decode(keyData)
-
-	//https://github.com/golang/crypto/blob/master/ssh/keys.go
-
-	fmt.Printf("Adding PEM key (%v) to ssh-agent\n", pemFilepath)
-
-	var privateKeyData interface{}
-	if x509.IsEncryptedPEMBlock(block) {
-		//inform the user that the key is encrypted.
-
-		passphrase, err := utils.StdinQueryPassword(fmt.Sprintf("The key at %v is encrypted and requires a passphrase. Please enter it below:", pemFilepath))
+	if e.Record || e.Config.GetString("options.transport") == "native" {
+		nativeTransport, err := transport.New(tmplConfigFilepath)
 		if err != nil {
 			return err
 		}
-
-		privateKeyData, err = ssh.ParseRawPrivateKeyWithPassphrase(keyData, []byte(passphrase))
-	} else {
-		privateKeyData, err = ssh.ParseRawPrivateKey(keyData)
+		fmt.Println("Opening ssh tunnel (native transport)")
+		e.printCommand(args, true)
+		if e.Record {
+			return e.recordShell(nativeTransport, configHost, answerData, &auditEntry)
+		}
+		return nativeTransport.Shell(configHost)
 	}
 
-	// register the privatekey with ssh-agent
+	//https://gobyexample.com/execing-processes
+	//https://groob.io/posts/golang-execve/
 
-	socket := os.Getenv("SSH_AUTH_SOCK")
-	conn, err := net.Dial("unix", socket)
-	if err != nil {
-		return err
+	fmt.Println("Opening ssh tunnel")
+	_, lookErr := exec.LookPath("ssh")
+	if lookErr != nil {
+		return errors.DependencyMissingError("ssh is missing")
 	}
-	agentClient := agent.NewClient(conn)
 
-	err = agentClient.Add(agent.AddedKey{
-		PrivateKey:   privateKeyData,
-		Comment:      fmt.Sprintf("(drawbridge) - %v", pemFilepath),
-		LifetimeSecs: 3600, //for safety we should limit this key's use for 1h
-	})
+	e.printCommand(args, false)
 
-	return err
+	// windows does not support exec -- simulate exec by running the command with the I/O wired to the parent process
+	cmd := exec.Command(args[0], args[1:len(args)]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
 }
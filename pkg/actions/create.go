@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/analogj/drawbridge/pkg/config"
 	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
 	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/fatih/color"
 	log "github.com/sirupsen/logrus"
@@ -20,26 +21,10 @@ type CreateAction struct {
 func (e *CreateAction) Start(cliAnswerData map[string]interface{}, dryRun bool) error {
 	log.Debugf("Answer Data: %v", cliAnswerData)
 
-	// prepare answer data with config.options
-	answerData := map[string]interface{}{}
-	e.Config.UnmarshalKey("options", &answerData)
-	log.Debugf("Current Options: %v", answerData)
-
-	// add defaults into answerData
-	questions, err := e.Config.GetQuestions()
+	questions, answerData, err := e.prepareAnswers(cliAnswerData)
 	if err != nil {
 		return err
 	}
-	for questionKey, question := range questions {
-		if question.DefaultValue != nil {
-			answerData[questionKey] = question.DefaultValue
-		}
-	}
-
-	// merge cliAnswerData into answerData
-	for cliAnswerKey, cliAnswerValue := range cliAnswerData {
-		answerData[cliAnswerKey] = cliAnswerValue
-	}
 
 	//log.Printf("answers found before questioning: %v \n", answerData)
 
@@ -60,22 +45,11 @@ func (e *CreateAction) Start(cliAnswerData map[string]interface{}, dryRun bool)
 	}
 
 	// ensure that that all questions are answered, query user if missing anything.
-	answerData, err = e.Query(questions, answerData)
+	answerData, err = e.completeAnswers(questions, answerData)
 	if err != nil {
 		return err
 	}
 
-	//set any optional keys to nil value.
-	for questionKey, question := range questions {
-		if !question.Required() {
-
-			if _, ok := answerData[questionKey]; !ok {
-				//answerdata does not contain this optional key
-				answerData[questionKey] = nil
-			}
-		}
-	}
-
 	// write the config template, make sure we "fix" the config filepath
 	activeConfigTemplate, err := e.Config.GetActiveConfigTemplate()
 	if err != nil {
@@ -90,6 +64,9 @@ func (e *CreateAction) Start(cliAnswerData map[string]interface{}, dryRun bool)
 	//make sure that we copy the config template data into the answerData object so it can be used by custom templates
 	//and is persisted in the answers.yaml file. Set it as key `config`
 	answerData["config"] = configTemplateData
+	if configFilePath, ok := configTemplateData["filepath"].(string); ok {
+		answerData["id"] = project.ConfigID(configFilePath)
+	}
 
 	// load up all active_custom_templates and attempt to merge answers with it.
 	activeCustomTemplates, err := e.Config.GetActiveCustomTemplates()
@@ -107,8 +84,82 @@ func (e *CreateAction) Start(cliAnswerData map[string]interface{}, dryRun bool)
 	}
 
 	// write the answers.yaml file
-	return e.WriteAnswersFile(filepath.Base(activeConfigTemplate.FilePath), answerData, dryRun)
+	err = e.WriteAnswersFile(filepath.Base(activeConfigTemplate.FilePath), answerData, dryRun)
+	if err != nil || dryRun {
+		return err
+	}
+
+	// the ssh config index is generated from the answers files on disk
+	indexAction := IndexAction{Config: e.Config}
+	return indexAction.Start(false)
+}
+
+// Render renders the config & custom templates for the provided answers in memory, without printing or writing
+// anything to disk. Used by `drawbridge create --dryrun --output json|yaml`
+func (e *CreateAction) Render(cliAnswerData map[string]interface{}) (RenderedProject, error) {
+	questions, answerData, err := e.prepareAnswers(cliAnswerData)
+	if err != nil {
+		return RenderedProject{}, err
+	}
+	answerData, err = e.completeAnswers(questions, answerData)
+	if err != nil {
+		return RenderedProject{}, err
+	}
+
+	regenerateAction := RegenerateAction{Config: e.Config}
+	return regenerateAction.Render(answerData)
+}
+
+// merges config.options, question defaults & the provided answers.
+func (e *CreateAction) prepareAnswers(cliAnswerData map[string]interface{}) (map[string]config.Question, map[string]interface{}, error) {
+	// prepare answer data with config.options
+	answerData := map[string]interface{}{}
+	e.Config.UnmarshalKey("options", &answerData)
+	log.Debugf("Current Options: %v", answerData)
+	for _, agentOptionKey := range AgentOptionKeys {
+		delete(answerData, agentOptionKey)
+	}
+	// the audit log is global, a config must not be able to redirect it.
+	delete(answerData, "audit_log")
+
+	// add defaults into answerData
+	questions, err := e.Config.GetQuestions()
+	if err != nil {
+		return nil, nil, err
+	}
+	for questionKey, question := range questions {
+		if question.DefaultValue != nil {
+			answerData[questionKey] = question.DefaultValue
+		}
+	}
+
+	// merge cliAnswerData into answerData
+	for cliAnswerKey, cliAnswerValue := range cliAnswerData {
+		answerData[cliAnswerKey] = cliAnswerValue
+	}
+	return questions, answerData, nil
+}
+
+// queries the user for any missing required answers, and sets missing optional answers to nil.
+func (e *CreateAction) completeAnswers(questions map[string]config.Question, answerData map[string]interface{}) (map[string]interface{}, error) {
+	answerData, err := e.Query(questions, answerData)
+	if err != nil {
+		return nil, err
+	}
+
+	//set any optional keys to nil value.
+	for questionKey, question := range questions {
+		if !question.Required() {
+
+			if _, ok := answerData[questionKey]; !ok {
+				//answerdata does not contain this optional key
+				answerData[questionKey] = nil
+			}
+		}
+	}
+	return answerData, nil
 }
+
 func (e *CreateAction) WriteAnswersFile(baseName string, answerData map[string]interface{}, dryRun bool) error {
 	answersFilePath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.config_dir"), fmt.Sprintf(".%v.answers.yaml", baseName)), answerData)
 	if err != nil {
@@ -141,7 +192,11 @@ func (e *CreateAction) Query(questions map[string]config.Question, answerData ma
 		required := ok && val.(bool)
 
 		if _, ok := answerData[questionKey]; !ok && required {
-			answerData[questionKey] = e.queryResponse(questionKey, questionData)
+			answerValue, err := e.queryResponse(questionKey, questionData)
+			if err != nil {
+				return nil, err
+			}
+			answerData[questionKey] = answerValue
 
 		}
 	}
@@ -149,11 +204,17 @@ func (e *CreateAction) Query(questions map[string]config.Question, answerData ma
 	return answerData, nil
 }
 
-func (e *CreateAction) queryResponse(questionKey string, question config.Question) interface{} {
+func (e *CreateAction) queryResponse(questionKey string, question config.Question) (interface{}, error) {
 
 	for true {
 		//this question is not answered, and it is required. We should ask the user.
-		answer := utils.StdinQuery(fmt.Sprintf("Please enter a value for `%s` [%s] - %s:", questionKey, question.GetType(), question.Description))
+		answer, err := utils.StdinQuery(fmt.Sprintf("Please enter a value for `%s` [%s] - %s:", questionKey, question.GetType(), question.Description))
+		if err != nil {
+			if _, ok := err.(errors.InteractivePromptError); ok {
+				return nil, errors.InteractivePromptError(fmt.Sprintf("Missing answer for required question `%s`, provide it with --%s", questionKey, questionKey))
+			}
+			return nil, err
+		}
 
 		answerTyped, err := convertAnswerType(answer, question.GetType())
 		if err != nil {
@@ -165,12 +226,12 @@ func (e *CreateAction) queryResponse(questionKey string, question config.Questio
 		if err != nil {
 			color.HiRed("%v\n", err)
 		} else {
-			return answerTyped
+			return answerTyped, nil
 		}
 
 	}
 	//return answerTyped
-	return nil
+	return nil, nil
 }
 
 func convertAnswerType(answer string, questionType string) (interface{}, error) {
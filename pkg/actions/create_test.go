@@ -3,6 +3,8 @@ package actions_test
 import (
 	"github.com/analogj/drawbridge/pkg/actions"
 	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"os"
@@ -68,3 +70,68 @@ func TestCreateAction_WriteAnswersFile(t *testing.T) {
 	//assert
 	require.NoError(t, err, "should not raise an error when adding writing answer file")
 }
+
+func TestCreateAction_Start_NonInteractiveMissingAnswer(t *testing.T) {
+	//not parallel, NonInteractive is a package level setting.
+	utils.NonInteractive = true
+	defer func() { utils.NonInteractive = false }()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	createAction := actions.CreateAction{
+		Config: configData,
+	}
+
+	//test
+	err = createAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "tested",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+	}, false)
+
+	//assert
+	require.IsType(t, errors.InteractivePromptError(""), err, "should not prompt for the missing username answer")
+	require.Equal(t, errors.ExitCodeInteractivePrompt, errors.ExitCode(err))
+}
+
+func TestCreateAction_Render(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	createAction := actions.CreateAction{
+		Config: configData,
+	}
+
+	//test
+	rendered, err := createAction.Render(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "tested",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	})
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rendered.Files))
+	require.Equal(t, filepath.Join(parentPath, "test-tested-live-us-east-1"), rendered.Files[0].FilePath)
+	require.Contains(t, rendered.Files[0].Content, "Host bastion")
+	require.Equal(t, filepath.Join(parentPath, ".test-tested-live-us-east-1.answers.yaml"), rendered.AnswersFilePath)
+	require.NoFileExists(t, rendered.Files[0].FilePath, "should not write anything to disk")
+}
@@ -3,6 +3,7 @@ package actions
 import (
 	"fmt"
 	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
 	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/fatih/color"
 	log "github.com/sirupsen/logrus"
@@ -14,16 +15,29 @@ type DeleteAction struct {
 	Config config.Interface
 }
 
+// All deletes every config, each is confirmed unless forced. Errors deleting one config do not stop the others, the
+// first one is returned once all configs were processed. Confirmations are impossible in non-interactive mode, so
+// nothing is deleted without --force.
 func (e *DeleteAction) All(answerDataList []map[string]interface{}, force bool) error {
+	if utils.NonInteractive && !force {
+		return errors.InteractivePromptError(fmt.Sprintf("Cannot confirm deleting %v config(s), use --force to delete without confirmation", len(answerDataList)))
+	}
 
+	var firstErr error
 	for _, v := range answerDataList {
 		err := e.One(v, force)
-		if err != nil {
-			color.Red("ERROR IGNORED: %v", err)
+		if _, isPromptErr := err.(errors.InteractivePromptError); isPromptErr {
+			return err
+		} else if err != nil {
+			color.Red("ERROR: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
-	return nil
+	return firstErr
 }
+
 func (e *DeleteAction) One(answerData map[string]interface{}, force bool) error {
 	log.Debugf("Answer Data: %v", answerData)
 
@@ -48,8 +62,10 @@ func (e *DeleteAction) One(answerData map[string]interface{}, force bool) error
 		}
 		questionStr = append(questionStr, "\nPlease confirm [yes/no]:")
 
-		val := utils.StdinQueryBoolean(strings.Join(questionStr, "\n"))
-		if !val {
+		val, err := utils.StdinQueryBoolean(strings.Join(questionStr, "\n"))
+		if err != nil {
+			return err
+		} else if !val {
 			color.Red("Cancelled delete operation.")
 			return nil
 		}
@@ -81,5 +97,6 @@ func (e *DeleteAction) One(answerData map[string]interface{}, force bool) error
 		color.Yellow(" - Skipping. Could not find answers file at: %v", answersFilePath)
 	}
 
-	return nil
+	indexAction := IndexAction{Config: e.Config}
+	return indexAction.Start(false)
 }
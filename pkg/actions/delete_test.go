@@ -3,6 +3,8 @@ package actions_test
 import (
 	"github.com/analogj/drawbridge/pkg/actions"
 	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
 	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
@@ -102,3 +104,85 @@ func TestDeleteAction_All(t *testing.T) {
 	require.False(t, utils.FileExists(filepath.Join(drawbridgePath, "prod-app-idle-us-east-1")), "test file should not be exist")
 
 }
+
+func TestDeleteAction_All_NonInteractive(t *testing.T) {
+	//not parallel, NonInteractive is a package level setting.
+	utils.NonInteractive = true
+	defer func() { utils.NonInteractive = false }()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	drawbridgePath := filepath.Join(parentPath, "drawbridge")
+	err = utils.CopyDir(filepath.Join("testdata", "delete"), drawbridgePath)
+	require.NoError(t, err)
+
+	configData.Set("options.config_dir", drawbridgePath)
+	deleteAction := actions.DeleteAction{
+		Config: configData,
+	}
+
+	//test
+	err = deleteAction.All([]map[string]interface{}{
+		{
+			"environment": "prod",
+			"config": map[string]interface{}{
+				"filepath": filepath.Join(drawbridgePath, "prod-app-idle-us-east-1"),
+			},
+			"config_dir": drawbridgePath,
+		},
+	}, false)
+
+	//assert
+	require.IsType(t, errors.InteractivePromptError(""), err, "should not exit successfully when the deletes can't be confirmed")
+	require.True(t, utils.FileExists(filepath.Join(drawbridgePath, "prod-app-idle-us-east-1")), "should not delete unconfirmed configs")
+}
+
+func TestDeleteAction_All_SelectorNonInteractive(t *testing.T) {
+	//not parallel, NonInteractive is a package level setting.
+	utils.NonInteractive = true
+	defer func() { utils.NonInteractive = false }()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	for _, shardType := range []string{"idle", "live"} {
+		require.NoError(t, createAction.Start(map[string]interface{}{
+			"environment": "prod",
+			"stack_name":  "app",
+			"shard":       "us-east-1",
+			"shard_type":  shardType,
+			"username":    "aws",
+		}, false))
+	}
+	projectList, err := project.CreateProjectListFromConfigDir(configData)
+	require.NoError(t, err)
+	answerDataList, _, err := projectList.GetAllWithAliasOrIndex("environment=prod")
+	require.NoError(t, err)
+	require.Len(t, answerDataList, 2)
+	deleteAction := actions.DeleteAction{Config: configData}
+
+	//test
+	err = deleteAction.All(answerDataList, false)
+
+	//assert
+	require.IsType(t, errors.InteractivePromptError(""), err, "should not exit successfully when a multi-match delete can't be confirmed")
+	require.True(t, utils.FileExists(filepath.Join(parentPath, "prod-app-idle-us-east-1")), "should not delete unconfirmed configs")
+	require.True(t, utils.FileExists(filepath.Join(parentPath, "prod-app-live-us-east-1")), "should not delete unconfirmed configs")
+}
@@ -4,8 +4,10 @@ package actions
 
 import (
 	"fmt"
+	"github.com/analogj/drawbridge/pkg/audit"
 	"github.com/analogj/drawbridge/pkg/config"
 	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/transport"
 	"github.com/analogj/drawbridge/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"os"
@@ -18,33 +20,55 @@ type DownloadAction struct {
 	Config config.Interface
 }
 
-func (e *DownloadAction) Start(answerData map[string]interface{}, destHostname string, remoteFilePath string, localFilePath string) error {
+func (e *DownloadAction) Start(answerData map[string]interface{}, destHostname string, remoteFilePath string, localFilePath string) (err error) {
 	log.Debugf("Answer Data: %v", answerData)
 
+	auditEntry := audit.Entry{Action: "download", Host: destHostname, RemotePath: remoteFilePath, LocalPath: localFilePath}
+	defer func() { e.audit(auditEntry, answerData, err) }()
+
 	tmplData, err := e.Config.GetActiveConfigTemplate()
 	if err != nil {
-		return nil
+		return err
 	}
 
 	tmplConfigFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.config_dir"), tmplData.FilePath), answerData)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	if e.Config.GetBool("options.bastion_preflight") && !e.ShellExport {
+		if err := e.BastionPreflight(tmplConfigFilepath); err != nil {
+			return err
+		}
+	}
+
+	pemFilepath := ""
 	if tmplData.PemFilePath != "" {
 		tmplPemFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.pem_dir"), tmplData.PemFilePath), answerData)
 		if err != nil {
-			return nil
+			return err
 		}
+		pemFilepath = tmplPemFilepath
 
-		//TODO: Print the lines we're running.
+		err = e.loadPemKey(tmplPemFilepath, answerData)
+		if err != nil {
+			return err
+		}
+	}
 
-		//TODO: Check that the bastion host is accessible.
+	args := []string{"scp", "-F", tmplConfigFilepath, fmt.Sprintf("%v.in:%v", destHostname, remoteFilePath), localFilePath}
+	if e.ShellExport {
+		return e.exportCommand(args, pemFilepath)
+	}
 
-		err = e.SshAgentAddPemKey(tmplPemFilepath)
+	if e.Config.GetString("options.transport") == "native" {
+		nativeTransport, err := transport.New(tmplConfigFilepath)
 		if err != nil {
 			return err
 		}
+		fmt.Println("Begin downloading file through bastion (native transport)")
+		e.printCommand(args, true)
+		return nativeTransport.Download(fmt.Sprintf("%v.in", destHostname), remoteFilePath, localFilePath, false)
 	}
 
 	fmt.Println("Begin downloading file through bastion")
@@ -53,8 +77,7 @@ func (e *DownloadAction) Start(answerData map[string]interface{}, destHostname s
 		return errors.DependencyMissingError("scp is missing")
 	}
 
-	args := []string{"scp", "-F", tmplConfigFilepath, fmt.Sprintf("%v.in:%v", destHostname, remoteFilePath), localFilePath}
-
+	e.printCommand(args, false)
 	// windows does not support exec -- simulate exec by running the command with the I/O wired to the parent process
 	cmd := exec.Command(args[0], args[1:len(args)]...)
 	cmd.Stdout = os.Stdout
@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+type EditAction struct {
+	Config config.Interface
+}
+
+// Start changes the answers of a saved config, then re-renders its config & custom templates (moving them if their
+// templated filepath changed). Answers provided as flags are validated and applied as-is, otherwise the user is
+// prompted for each question. Internal answers (id, aliases, tags, notes, etc) are preserved.
+func (e *EditAction) Start(answerData map[string]interface{}, cliAnswerData map[string]interface{}, dryRun bool) error {
+	log.Debugf("Answer Data: %v", answerData)
+
+	editedAnswerData, err := e.Edit(answerData, cliAnswerData)
+	if err != nil {
+		return err
+	}
+
+	changedKeys := changedAnswerKeys(answerData, editedAnswerData)
+	if len(changedKeys) == 0 {
+		color.Green("No answers changed for %v", answerData["config"].(map[string]interface{})["filepath"])
+		return nil
+	}
+	fmt.Println("\nChanged Answers:")
+	for _, questionKey := range changedKeys {
+		fmt.Printf("%v: %v → %v\n", questionKey, color.RedString("%v", answerData[questionKey]), color.GreenString("%v", editedAnswerData[questionKey]))
+	}
+
+	// the edited config must not be moved on top of another config
+	regenerateAction := RegenerateAction{Config: e.Config}
+	rendered, err := regenerateAction.Render(editedAnswerData)
+	if err != nil {
+		return err
+	}
+	if rendered.AnswersFilePath != rendered.PreviousAnswersFilePath && utils.FileExists(rendered.AnswersFilePath) {
+		return errors.ConfigValidationError(fmt.Sprintf("the edited answers would overwrite another drawbridge config (%v)", rendered.AnswersFilePath))
+	}
+
+	if err := regenerateAction.One(editedAnswerData, dryRun); err != nil {
+		return err
+	}
+
+	// the config may have been moved
+	indexAction := IndexAction{Config: e.Config}
+	return indexAction.Start(dryRun)
+}
+
+// Edit returns a copy of the answers with the changes applied, without writing anything to disk.
+func (e *EditAction) Edit(answerData map[string]interface{}, cliAnswerData map[string]interface{}) (map[string]interface{}, error) {
+	questions, err := e.Config.GetQuestions()
+	if err != nil {
+		return nil, err
+	}
+
+	// only top level answers are replaced, so a shallow copy is enough
+	editedAnswerData := map[string]interface{}{}
+	for answerKey, answerValue := range answerData {
+		editedAnswerData[answerKey] = answerValue
+	}
+
+	if len(cliAnswerData) == 0 {
+		return e.Query(questions, editedAnswerData)
+	}
+
+	for _, questionKey := range utils.MapKeys(cliAnswerData) {
+		question, isQuestion := questions[questionKey]
+		if !isQuestion {
+			return nil, errors.InvalidArgumentsError(fmt.Sprintf("`%v` is not a question in drawbridge.yaml", questionKey))
+		}
+		if err := question.Validate(questionKey, cliAnswerData[questionKey]); err != nil {
+			return nil, err
+		}
+		editedAnswerData[questionKey] = cliAnswerData[questionKey]
+	}
+	return editedAnswerData, nil
+}
+
+// Query prompts for a new value for every question, showing the current answer. An empty response keeps the current
+// answer.
+func (e *EditAction) Query(questions map[string]config.Question, answerData map[string]interface{}) (map[string]interface{}, error) {
+	questionKeys := []string{}
+	for k := range questions {
+		questionKeys = append(questionKeys, k)
+	}
+	sort.Strings(questionKeys)
+
+	for _, questionKey := range questionKeys {
+		question := questions[questionKey]
+		for {
+			answer, err := utils.StdinQuery(fmt.Sprintf("Please enter a value for `%s` [%s] - %s (current: %v, leave empty to keep):", questionKey, question.GetType(), question.Description, answerData[questionKey]))
+			if err != nil {
+				if _, ok := err.(errors.InteractivePromptError); ok {
+					return nil, errors.InteractivePromptError(fmt.Sprintf("Cannot prompt for `%s`, provide the changed answers as flags (eg. --%s)", questionKey, questionKey))
+				}
+				return nil, err
+			}
+			if len(answer) == 0 {
+				break
+			}
+
+			answerTyped, err := convertAnswerType(answer, question.GetType())
+			if err != nil {
+				fmt.Printf("%v\n", err)
+				continue
+			}
+			if err := question.Validate(questionKey, answerTyped); err != nil {
+				color.HiRed("%v\n", err)
+				continue
+			}
+			answerData[questionKey] = answerTyped
+			break
+		}
+	}
+	return answerData, nil
+}
+
+// returns the (sorted) keys whose values differ between the answers
+func changedAnswerKeys(answerData map[string]interface{}, editedAnswerData map[string]interface{}) []string {
+	changedKeys := []string{}
+	for _, answerKey := range utils.MapKeys(editedAnswerData) {
+		if fmt.Sprintf("%v", answerData[answerKey]) != fmt.Sprintf("%v", editedAnswerData[answerKey]) {
+			changedKeys = append(changedKeys, answerKey)
+		}
+	}
+	return changedKeys
+}
@@ -0,0 +1,108 @@
+package actions_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createEditTestConfig(t *testing.T, parentPath string) config.Interface {
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	err = createAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, false)
+	require.NoError(t, err)
+	return configData
+}
+
+func TestEditAction_Start(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configData := createEditTestConfig(t, parentPath)
+
+	projectList, err := project.CreateProjectListFromConfigDir(configData)
+	require.NoError(t, err)
+	_, err = projectList.AddAliasForIndex(0, "edited")
+	require.NoError(t, err)
+	_, err = projectList.AddTagsForIndex(0, []string{"primary"})
+	require.NoError(t, err)
+	answerData, _, err := projectList.GetWithIndex(0)
+	require.NoError(t, err)
+	editAction := actions.EditAction{Config: configData}
+
+	//test
+	err = editAction.Start(answerData, map[string]interface{}{"shard_type": "idle", "username": "ubuntu"}, false)
+
+	//assert
+	require.NoError(t, err)
+	require.False(t, utils.FileExists(filepath.Join(parentPath, "test-app-live-us-east-1")), "should remove the previous config file")
+	require.False(t, utils.FileExists(filepath.Join(parentPath, ".test-app-live-us-east-1.answers.yaml")), "should remove the previous answers file")
+	content, err := ioutil.ReadFile(filepath.Join(parentPath, "test-app-idle-us-east-1"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "User ubuntu")
+
+	reloadedList, err := project.CreateProjectListFromConfigDir(configData)
+	require.NoError(t, err)
+	require.Equal(t, 1, reloadedList.Length())
+	editedAnswerData, _, err := reloadedList.GetWithAlias("edited")
+	require.NoError(t, err, "should preserve the alias")
+	require.Equal(t, "idle", editedAnswerData["shard_type"])
+	require.Equal(t, []string{"primary"}, project.Tags(editedAnswerData), "should preserve the tags")
+	require.Equal(t, answerData["id"], editedAnswerData["id"], "should preserve the config id")
+	require.Equal(t, filepath.Join(parentPath, "test-app-idle-us-east-1"), editedAnswerData["config"].(map[string]interface{})["filepath"])
+}
+
+func TestEditAction_Edit_Invalid(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configData := createEditTestConfig(t, parentPath)
+
+	projectList, err := project.CreateProjectListFromConfigDir(configData)
+	require.NoError(t, err)
+	answerData, _, err := projectList.GetWithIndex(0)
+	require.NoError(t, err)
+	editAction := actions.EditAction{Config: configData}
+
+	//test
+	_, unknownErr := editAction.Edit(answerData, map[string]interface{}{"not_a_question": "value"})
+	_, invalidErr := editAction.Edit(answerData, map[string]interface{}{"username": 1})
+	editedAnswerData, err := editAction.Edit(answerData, map[string]interface{}{"username": "ubuntu"})
+
+	//assert
+	require.Error(t, unknownErr)
+	require.Error(t, invalidErr, "should validate the answers with the question schema")
+	require.NoError(t, err)
+	require.Equal(t, "ubuntu", editedAnswerData["username"])
+	require.Equal(t, "aws", answerData["username"], "should not modify the loaded answers")
+}
@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+type ExecAction struct {
+	ConnectAction
+	Config config.Interface
+}
+
+// ExecResult is the outcome of running the command on a single internal host.
+type ExecResult struct {
+	Host       string `json:"host" yaml:"host"`
+	ExitStatus int    `json:"exit_status" yaml:"exit_status"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Start runs command on each of the internal hosts (at most `parallel` at a time) through the bastion of the config,
+// prefixing each line of output with the hostname. Returns a RemoteCommandError if the command failed on any host.
+func (e *ExecAction) Start(answerData map[string]interface{}, hosts []string, command []string, parallel int, outputFormat string) error {
+	log.Debugf("Answer Data: %v", answerData)
+
+	if len(hosts) == 0 {
+		return errors.InvalidArgumentsError("at least one internal host must be specified with --hosts")
+	}
+	if len(command) == 0 {
+		return errors.InvalidArgumentsError("a command must be specified after `--`")
+	}
+	if parallel <= 0 {
+		parallel = len(hosts)
+	}
+
+	configFilepath, pemFilepath, err := renderedFilepaths(e.Config, answerData)
+	if err != nil {
+		return err
+	}
+	if len(pemFilepath) > 0 {
+		if err := e.SshAgentAddPemKey(pemFilepath, answerData); err != nil {
+			return err
+		}
+	}
+
+	run, closeRunner, err := e.runner(configFilepath)
+	if err != nil {
+		return err
+	}
+	defer closeRunner()
+
+	// keep STDOUT machine-readable when printing json/yaml results
+	var output io.Writer = color.Output
+	if utils.IsStructuredOutput(outputFormat) {
+		output = os.Stderr
+	}
+
+	remoteCommand := strings.Join(command, " ")
+	results := make([]ExecResult, len(hosts))
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for ndx, host := range hosts {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(ndx int, host string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			stdout := newHostLineWriter(output, host, "stdout", color.GreenString)
+			stderr := newHostLineWriter(output, host, "stderr", color.RedString)
+			exitStatus, err := run(host, remoteCommand, stdout, stderr)
+			stdout.Close()
+			stderr.Close()
+
+			results[ndx] = ExecResult{Host: host, ExitStatus: exitStatus}
+			if err != nil {
+				results[ndx].Error = err.Error()
+			}
+		}(ndx, host)
+	}
+	wg.Wait()
+
+	return printExecResults(results, outputFormat)
+}
+
+type execRunner func(host string, command string, stdout io.Writer, stderr io.Writer) (int, error)
+
+// returns a function that runs a command on an internal host, using the configured transport.
+// The native transport shares a single bastion connection between all hosts.
+func (e *ExecAction) runner(configFilepath string) (execRunner, func(), error) {
+	if e.Config.GetString("options.transport") == "native" {
+		nativeTransport, err := transport.New(configFilepath)
+		if err != nil {
+			return nil, nil, err
+		}
+		bastion, err := nativeTransport.DialBastion()
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(host string, command string, stdout io.Writer, stderr io.Writer) (int, error) {
+			return nativeTransport.RunThrough(bastion, fmt.Sprintf("%v.in", host), command, stdout, stderr)
+		}, func() { bastion.Close() }, nil
+	}
+
+	sshBin, lookErr := exec.LookPath("ssh")
+	if lookErr != nil {
+		return nil, nil, errors.DependencyMissingError("ssh is missing")
+	}
+	return func(host string, command string, stdout io.Writer, stderr io.Writer) (int, error) {
+		cmd := exec.Command(sshBin, "-F", configFilepath, "-o", "BatchMode=yes", fmt.Sprintf("%v.in", host), command)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		err := cmd.Run()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		} else if err != nil {
+			return -1, err
+		}
+		return 0, nil
+	}, func() {}, nil
+}
+
+func printExecResults(results []ExecResult, outputFormat string) error {
+	failed := []string{}
+	for _, result := range results {
+		if result.ExitStatus != 0 || len(result.Error) > 0 {
+			failed = append(failed, result.Host)
+		}
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		if err := utils.PrintStructured(os.Stdout, outputFormat, results); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		for _, result := range results {
+			if len(result.Error) > 0 {
+				fmt.Printf("%v: %v\n", color.RedString(result.Host), result.Error)
+			} else if result.ExitStatus != 0 {
+				fmt.Printf("%v: exit status %v\n", color.RedString(result.Host), result.ExitStatus)
+			} else {
+				fmt.Printf("%v: exit status 0\n", color.GreenString(result.Host))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.RemoteCommandError(fmt.Sprintf("command failed on %v of %v hosts: %v", len(failed), len(results), strings.Join(failed, ", ")))
+	}
+	return nil
+}
+
+// hostLineWriter prefixes each line of remote output with the hostname & stream, similar to the logstreamer used by
+// utils.CmdExec. Lines are written atomically, so output from parallel hosts is never interleaved mid-line.
+type hostLineWriter struct {
+	out    io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+var hostLineWriterLock sync.Mutex
+
+func newHostLineWriter(out io.Writer, host string, stream string, colorFn func(format string, a ...interface{}) string) *hostLineWriter {
+	return &hostLineWriter{out: out, prefix: fmt.Sprintf("%v | %v ", host, colorFn(stream))}
+}
+
+func (w *hostLineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// incomplete line, keep it buffered until the next write (or Close)
+			w.buf.WriteString(line)
+			break
+		}
+		w.output(line)
+	}
+	return len(p), nil
+}
+
+// Close flushes any remaining output that did not end with a newline.
+func (w *hostLineWriter) Close() error {
+	if w.buf.Len() > 0 {
+		w.output(w.buf.String() + "\n")
+		w.buf.Reset()
+	}
+	return nil
+}
+
+func (w *hostLineWriter) output(line string) {
+	hostLineWriterLock.Lock()
+	defer hostLineWriterLock.Unlock()
+	fmt.Fprint(w.out, w.prefix+line)
+}
@@ -0,0 +1,53 @@
+// +build linux darwin
+
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExecAction_Start(t *testing.T) {
+	//not parallel, PATH is modified to use a fake ssh binary.
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+
+	// the fake ssh binary fails on hosts starting with `bad`
+	err = ioutil.WriteFile(filepath.Join(parentPath, "ssh"), []byte("#!/bin/sh\nfor last; do true; done\ncase \"$5\" in bad*) echo \"failed on $5\" >&2; exit 3;; esac\necho \"$last on $5\"\n"), 0755)
+	require.NoError(t, err)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", parentPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath": "{{.environment}}-{{.shard}}",
+			"content":  "Host bastion\n",
+		},
+	})
+	execAction := actions.ExecAction{Config: configData}
+	answerData := map[string]interface{}{"environment": "test", "shard": "us-east-1"}
+
+	//test
+	okErr := execAction.Start(answerData, []string{"web1", "web2"}, []string{"uptime"}, 1, utils.OutputFormatText)
+	failedErr := execAction.Start(answerData, []string{"web1", "bad1"}, []string{"uptime"}, 0, utils.OutputFormatJson)
+	missingCommandErr := execAction.Start(answerData, []string{"web1"}, []string{}, 0, utils.OutputFormatText)
+
+	//assert
+	require.NoError(t, okErr)
+	require.IsType(t, errors.RemoteCommandError(""), failedErr, "should return an aggregated error when a host fails")
+	require.Contains(t, failedErr.Error(), "1 of 2 hosts: bad1")
+	require.IsType(t, errors.InvalidArgumentsError(""), missingCommandErr)
+}
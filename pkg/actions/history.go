@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// HistoryAction queries the audit log of connect/download/upload sessions.
+type HistoryAction struct {
+	Config config.Interface
+}
+
+// Entries returns the audit log entries matching the filter, oldest first. If limit is positive, only the most recent
+// entries are returned.
+func (e *HistoryAction) Entries(filter audit.Filter, limit int) ([]audit.Entry, error) {
+	auditLogPath, err := audit.FilePath(e.Config)
+	if err != nil {
+		return nil, err
+	} else if len(auditLogPath) == 0 {
+		return nil, errors.ConfigValidationError("the audit log is disabled (options.audit_log is empty)")
+	}
+
+	entries, err := audit.Read(auditLogPath, filter)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// List prints the audit log entries matching the filter.
+func (e *HistoryAction) List(filter audit.Filter, limit int, outputFormat string) error {
+	entries, err := e.Entries(filter, limit)
+	if err != nil {
+		return err
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No matching sessions found in the audit log")
+		return nil
+	}
+	for _, entry := range entries {
+		config := entry.ConfigFilepath
+		aliases := entry.Aliases
+		if len(aliases) == 0 && len(entry.Alias) > 0 {
+			aliases = []string{entry.Alias}
+		}
+		if len(aliases) > 0 {
+			config = fmt.Sprintf("%v (%v)", config, strings.Join(aliases, ", "))
+		}
+		destination := entry.Host
+		if len(entry.RemotePath) > 0 {
+			destination = fmt.Sprintf("%v:%v", entry.Host, entry.RemotePath)
+		}
+
+		outcome := color.GreenString(entry.Outcome)
+		if entry.Outcome == audit.OutcomeFailure {
+			outcome = color.RedString(entry.Outcome)
+		} else if entry.Outcome == audit.OutcomeStarted {
+			outcome = color.YellowString(entry.Outcome)
+		}
+
+		fmt.Printf("%v %v %-8v %v → %v %v\n", entry.Timestamp.Local().Format("2006-01-02 15:04:05"), entry.User, entry.Action, config, destination, outcome)
+		if len(entry.Recording) > 0 {
+			fmt.Printf("\trecording: %v\n", entry.Recording)
+		}
+		if len(entry.Error) > 0 {
+			fmt.Printf("\t%v\n", entry.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAction_Entries(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "test-app-live-us-east-1"), []byte("Host bastion\n  Hostname bastion.invalid\n"), 0600))
+
+	answerData := map[string]interface{}{"environment": "test", "stack_name": "app", "shard_type": "live", "shard": "us-east-1", "username": "aws", "id": "1a2b3c4d", "aliases": []string{"test", "live"}}
+	downloadAction := actions.DownloadAction{ConnectAction: actions.ConnectAction{Config: configData}, Config: configData}
+	historyAction := actions.HistoryAction{Config: configData}
+
+	//test
+	downloadErr := downloadAction.Start(answerData, "database-1", "/tmp/dump.sql", "dump.sql")
+	entries, err := historyAction.Entries(audit.Filter{Alias: "live"}, 0)
+	require.NoError(t, err)
+	idEntries, err := historyAction.Entries(audit.Filter{Alias: "1a2b3c4d"}, 0)
+
+	//assert
+	require.Error(t, downloadErr, "should fail the bastion pre-flight")
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "should match any alias of the config")
+	require.Len(t, idEntries, 1, "should match the config ID")
+	require.Equal(t, "1a2b3c4d", entries[0].ID)
+	require.Equal(t, []string{"test", "live"}, entries[0].Aliases)
+	require.Equal(t, "download", entries[0].Action)
+	require.Equal(t, "database-1", entries[0].Host)
+	require.Equal(t, "/tmp/dump.sql", entries[0].RemotePath)
+	require.Equal(t, audit.OutcomeFailure, entries[0].Outcome)
+	require.Equal(t, filepath.Join(parentPath, "test-app-live-us-east-1"), entries[0].ConfigFilepath)
+	require.Equal(t, "test", entries[0].Answers["environment"])
+	require.NotContains(t, entries[0].Answers, "aliases", "should not record internal keys as answers")
+}
@@ -0,0 +1,177 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+// IndexAction maintains a single ssh config file that can be `Include`d from ~/.ssh/config, so that plain `ssh`,
+// `scp` and `rsync` work without `drawbridge connect`.
+// For each drawbridge managed config, the `bastion` host is renamed `<name>-bastion` and `bastion+<host>` is renamed
+// `<name>+<host>`, where name is the config filename and (if set) the config alias. Jump host aliases used by the
+// bastion's ProxyJump chain are renamed `<name>-<hop>`.
+type IndexAction struct {
+	Config config.Interface
+}
+
+// FilePath returns the absolute path of the index file, or an empty string if `options.ssh_config_index` is disabled.
+func (e *IndexAction) FilePath() (string, error) {
+	indexFilePath := e.Config.GetString("options.ssh_config_index")
+	if len(indexFilePath) == 0 {
+		return "", nil
+	}
+	configDir, err := utils.ExpandPath(e.Config.GetString("options.config_dir"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, indexFilePath), nil
+}
+
+// Start regenerates the index file from all the answers files in the config_dir.
+func (e *IndexAction) Start(dryRun bool) error {
+	indexFilePath, err := e.FilePath()
+	if err != nil || len(indexFilePath) == 0 {
+		return err
+	}
+
+	projectList, err := project.CreateProjectListFromConfigDir(e.Config)
+	if err != nil {
+		return err
+	}
+
+	content, err := e.Render(projectList.GetAll())
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Writing ssh config index to %v", indexFilePath)
+	if !dryRun {
+		if err := os.MkdirAll(filepath.Dir(indexFilePath), 0777); err != nil {
+			return err
+		}
+	}
+	return utils.FileWrite(indexFilePath, content, 0600, dryRun)
+}
+
+// Render generates the content of the index file for the provided answers.
+func (e *IndexAction) Render(answerDataList []map[string]interface{}) (string, error) {
+	indexFilePath, err := e.FilePath()
+	if err != nil {
+		return "", err
+	}
+
+	content := []string{
+		"# This file was automatically generated by Drawbridge",
+		"# Do not modify. Add the following line to the top of ~/.ssh/config:",
+		"#",
+		fmt.Sprintf("#   Include %v", indexFilePath),
+		"#",
+	}
+
+	for _, answerData := range answerDataList {
+		configData, ok := answerData["config"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		configFilePath, _ := configData["filepath"].(string)
+		if !utils.FileExists(configFilePath) {
+			color.Yellow(" - Skipping. Could not find config file at: %v", configFilePath)
+			continue
+		}
+
+		names := []string{filepath.Base(configFilePath)}
+		names = append(names, project.Aliases(answerData)...)
+
+		sshConfig, err := sshconfig.ParseFile(configFilePath)
+		if err != nil {
+			return "", err
+		}
+
+		content = append(content, "", fmt.Sprintf("# %v", configFilePath))
+		content = append(content, indexHostBlocks(sshConfig, names)...)
+	}
+	return strings.Join(content, "\n") + "\n", nil
+}
+
+// renames the bastion host blocks of a drawbridge config, and copies the global options into each block (global
+// options cannot be copied as-is, since they would apply to every host in ~/.ssh/config)
+func indexHostBlocks(sshConfig *sshconfig.Config, names []string) []string {
+	globalOptions := []sshconfig.Option{}
+	for _, block := range sshConfig.Blocks {
+		if len(block.Patterns) == 1 && block.Patterns[0] == "*" {
+			globalOptions = append(globalOptions, block.Options...)
+		}
+	}
+
+	// jump hosts that are defined as host aliases in the config must be renamed too.
+	jumpHosts := transport.JumpHosts(sshConfig)
+	hopAliases := []string{}
+	for _, block := range sshConfig.Blocks {
+		for _, pattern := range block.Patterns {
+			if utils.SliceIncludes(jumpHosts, pattern) && !utils.SliceIncludes(hopAliases, pattern) {
+				hopAliases = append(hopAliases, pattern)
+			}
+		}
+	}
+
+	lines := []string{}
+	for _, block := range sshConfig.Blocks {
+		patterns := []string{}
+		for _, pattern := range block.Patterns {
+			for _, name := range names {
+				if pattern == transport.BastionHostAlias || utils.SliceIncludes(hopAliases, pattern) {
+					patterns = append(patterns, fmt.Sprintf("%v-%v", name, pattern))
+				} else if strings.HasPrefix(pattern, transport.BastionHostAlias+"+") {
+					patterns = append(patterns, name+strings.TrimPrefix(pattern, transport.BastionHostAlias))
+				}
+			}
+		}
+		if len(patterns) == 0 {
+			// only the bastion & jump hosts can be renamed safely.
+			continue
+		}
+
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, fmt.Sprintf("Host %v", strings.Join(patterns, " ")))
+		blockKeys := []string{}
+		for _, option := range block.Options {
+			value := option.Value
+			if option.Key == "proxyjump" {
+				value = indexProxyJump(value, names[0], hopAliases)
+			}
+			lines = append(lines, fmt.Sprintf("  %v %v", option.Key, value))
+			blockKeys = append(blockKeys, option.Key)
+		}
+		for _, option := range globalOptions {
+			if !utils.SliceIncludes(blockKeys, option.Key) {
+				lines = append(lines, fmt.Sprintf("  %v %v", option.Key, option.Value))
+			}
+		}
+	}
+	return lines
+}
+
+// rewrites the ProxyJump chain to use the renamed jump host aliases
+func indexProxyJump(proxyJump string, name string, hopAliases []string) string {
+	jumpHosts := strings.Split(proxyJump, ",")
+	for ndx, jumpHost := range jumpHosts {
+		jumpHost = strings.TrimSpace(jumpHost)
+		if utils.SliceIncludes(hopAliases, jumpHost) {
+			jumpHost = fmt.Sprintf("%v-%v", name, jumpHost)
+		}
+		jumpHosts[ndx] = jumpHost
+	}
+	return strings.Join(jumpHosts, ",")
+}
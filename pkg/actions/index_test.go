@@ -0,0 +1,90 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexAction_Render(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", "/tmp/drawbridge")
+	indexAction := actions.IndexAction{Config: configData}
+
+	//test
+	content, err := indexAction.Render([]map[string]interface{}{
+		{
+			"alias": "idle",
+			"config": map[string]interface{}{
+				"filepath": filepath.Join("testdata", "index", "prod-app-idle-us-east-1"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	indexConfig, err := sshconfig.Parse(strings.NewReader(content))
+
+	//assert
+	require.NoError(t, err)
+	require.Contains(t, content, "#   Include /tmp/drawbridge/config")
+	require.Equal(t, "bastion1.idle.us-east-1.appexample.com", indexConfig.Get("idle-bastion", "hostname"))
+	require.Equal(t, "bastion1.idle.us-east-1.appexample.com", indexConfig.Get("prod-app-idle-us-east-1-bastion", "hostname"))
+	require.Equal(t, "yes", indexConfig.Get("idle-bastion", "forwardagent"), "global options should be copied into each host")
+	require.Contains(t, indexConfig.Get("idle+web", "proxycommand"), "-F /Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1")
+	require.False(t, indexConfig.HasHost("bastion"), "should not define the generic bastion host")
+	require.Equal(t, 1, len(indexConfig.GetAll("idle-bastion", "stricthostkeychecking")), "should not duplicate options set in the host block")
+}
+
+func TestIndexAction_FilePath_Disabled(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.ssh_config_index", "")
+	indexAction := actions.IndexAction{Config: configData}
+
+	//test
+	indexFilePath, err := indexAction.FilePath()
+
+	//assert
+	require.NoError(t, err)
+	require.Empty(t, indexFilePath, "should disable the index when ssh_config_index is empty")
+	require.NoError(t, indexAction.Start(false))
+}
+
+func TestIndexAction_Render_Hops(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", "/tmp/drawbridge")
+	indexAction := actions.IndexAction{Config: configData}
+
+	//test
+	content, err := indexAction.Render([]map[string]interface{}{
+		{
+			"alias": "live",
+			"config": map[string]interface{}{
+				"filepath": filepath.Join("testdata", "index", "prod-app-live-eu-west-1"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	indexConfig, err := sshconfig.Parse(strings.NewReader(content))
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "edge1.appexample.com", indexConfig.Get("live-edge", "hostname"), "jump host aliases should be renamed")
+	require.Equal(t, "yes", indexConfig.Get("live-edge", "forwardagent"), "global options should be copied into jump hosts")
+	require.Equal(t, "prod-app-live-eu-west-1-edge,cloud-user@regional.appexample.com:2222", indexConfig.Get("live-bastion", "proxyjump"), "ProxyJump should reference the renamed jump hosts")
+	require.False(t, indexConfig.HasHost("edge"), "should not define the generic jump host")
+}
@@ -0,0 +1,355 @@
+package actions
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/secrets"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// the PEM key exists at its pem_filepath
+	KeyStatusPresent = "present"
+	// the PEM key is missing from the pem_dir, but was imported into the secret store
+	KeyStatusStored = "stored"
+	// the PEM key cannot be found
+	KeyStatusMissing = "missing"
+)
+
+type KeyAction struct {
+	Config config.Interface
+}
+
+// KeyInfo describes a PEM key referenced by one or more drawbridge managed configs
+type KeyInfo struct {
+	PemFilePath string   `json:"pem_filepath" yaml:"pem_filepath"`
+	Configs     []string `json:"configs" yaml:"configs"`
+	Status      string   `json:"status" yaml:"status"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	Encrypted   bool     `json:"encrypted" yaml:"encrypted"`
+	Error       string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// KeyImportOptions change where `drawbridge key import` puts the PEM key
+type KeyImportOptions struct {
+	// store the PEM key in the secret store, instead of copying it into the pem_dir
+	SecretStore bool
+	// only store the passphrase of the encrypted PEM key, leaving the key where it is
+	PassphraseOnly bool
+	// overwrite a different PEM key that already exists at the pem_filepath
+	Force bool
+}
+
+// List prints the PEM keys referenced by the drawbridge managed configs, and whether they are present, their type,
+// fingerprint & encryption.
+func (e *KeyAction) List(outputFormat string) error {
+	keyInfos, err := e.Keys()
+	if err != nil {
+		return err
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, keyInfos)
+	}
+
+	for _, keyInfo := range keyInfos {
+		if keyInfo.Status == KeyStatusMissing {
+			color.Red("✘ %v (%v)", keyInfo.PemFilePath, keyInfo.Status)
+		} else {
+			color.Green("✔ %v (%v)", keyInfo.PemFilePath, keyInfo.Status)
+		}
+		if len(keyInfo.Fingerprint) > 0 {
+			encrypted := ""
+			if keyInfo.Encrypted {
+				encrypted = color.YellowString(" (encrypted)")
+			}
+			fmt.Printf("    %v %v%v\n", keyInfo.Type, keyInfo.Fingerprint, encrypted)
+		}
+		if len(keyInfo.Error) > 0 {
+			fmt.Printf("    %v\n", color.RedString(keyInfo.Error))
+		}
+		fmt.Printf("    configs: %v\n", strings.Join(keyInfo.Configs, ", "))
+	}
+	return nil
+}
+
+// Keys returns the PEM keys referenced by the drawbridge managed configs, sorted by pem_filepath
+func (e *KeyAction) Keys() ([]KeyInfo, error) {
+	projectList, err := project.CreateProjectListFromConfigDir(e.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfoLookup := map[string]*KeyInfo{}
+	for _, entry := range projectList.GetAllEntries() {
+		if len(entry.PemFilePath) == 0 {
+			continue
+		}
+		configName := strconv.Itoa(entry.Index)
+		if len(entry.Alias) > 0 {
+			configName = fmt.Sprintf("%v (%v)", entry.Index, entry.Alias)
+		}
+		if keyInfo, ok := keyInfoLookup[entry.PemFilePath]; ok {
+			keyInfo.Configs = append(keyInfo.Configs, configName)
+		} else {
+			keyInfoLookup[entry.PemFilePath] = &KeyInfo{PemFilePath: entry.PemFilePath, Configs: []string{configName}}
+		}
+	}
+
+	// the secret store is only opened if a key is missing from the pem_dir, since the file store prompts for a password
+	var secretStore secrets.Store
+	keyInfos := []KeyInfo{}
+	for _, keyInfo := range keyInfoLookup {
+		keyData, err := ioutil.ReadFile(keyInfo.PemFilePath)
+		if err == nil {
+			keyInfo.Status = KeyStatusPresent
+		} else {
+			keyInfo.Status = KeyStatusMissing
+			if secretStore == nil {
+				if secretStore, err = secrets.New(e.Config); err != nil {
+					return nil, err
+				}
+			}
+			storedKeyData, found, err := secretStore.Get(secrets.PemKey(keyInfo.PemFilePath))
+			if err != nil {
+				return nil, err
+			} else if found {
+				keyInfo.Status = KeyStatusStored
+				keyData = []byte(storedKeyData)
+			}
+		}
+
+		if keyInfo.Status != KeyStatusMissing {
+			publicKey, encrypted, err := pemPublicKey(keyInfo.PemFilePath, keyData)
+			keyInfo.Encrypted = encrypted
+			if err != nil {
+				keyInfo.Error = err.Error()
+			} else if publicKey != nil {
+				keyInfo.Type = publicKey.Type()
+				keyInfo.Fingerprint = ssh.FingerprintSHA256(publicKey)
+			}
+		}
+		keyInfos = append(keyInfos, *keyInfo)
+	}
+
+	sort.Slice(keyInfos, func(i, j int) bool {
+		return keyInfos[i].PemFilePath < keyInfos[j].PemFilePath
+	})
+	return keyInfos, nil
+}
+
+// Import copies a PEM key into the templated pem_filepath of the config (with 0600 permissions). If the key is
+// encrypted, its passphrase is stored in the secret store, so `drawbridge connect` does not need to prompt for it.
+// See KeyImportOptions to store the PEM key itself in the secret store instead.
+func (e *KeyAction) Import(answerData map[string]interface{}, keyFilepath string, options KeyImportOptions) error {
+	pemFilepath, err := e.pemFilepath(answerData)
+	if err != nil {
+		return err
+	}
+
+	keyFilepath, err = utils.ExpandPath(keyFilepath)
+	if err != nil {
+		return err
+	}
+	keyData, err := ioutil.ReadFile(keyFilepath)
+	if err != nil {
+		return errors.PemKeyMissingError(fmt.Sprintf("Could not read pem file at %v: %v", keyFilepath, err))
+	}
+
+	passphrase := ""
+	privateKeyData, err := ssh.ParseRawPrivateKey(keyData)
+	if _, encrypted := err.(*ssh.PassphraseMissingError); encrypted {
+		passphrase, err = utils.StdinQueryPassword(fmt.Sprintf("The key at %v is encrypted. Please enter its passphrase to store it in the secret store:", keyFilepath))
+		if err != nil {
+			return err
+		}
+		privateKeyData, err = ssh.ParseRawPrivateKeyWithPassphrase(keyData, []byte(passphrase))
+	}
+	if err != nil {
+		return err
+	}
+	if options.PassphraseOnly && len(passphrase) == 0 {
+		return errors.InvalidArgumentsError(fmt.Sprintf("the key at %v is not encrypted, there is no passphrase to store", keyFilepath))
+	}
+
+	if !options.SecretStore && !options.PassphraseOnly && keyFilepath != pemFilepath {
+		if err := writePemKey(pemFilepath, keyData, options.Force); err != nil {
+			return err
+		}
+		color.Green("Copied %v to %v", keyFilepath, pemFilepath)
+	}
+
+	if !options.SecretStore && len(passphrase) == 0 {
+		return nil
+	}
+
+	secretStore, err := secrets.New(e.Config)
+	if err != nil {
+		return err
+	}
+	if options.SecretStore {
+		if err := secretStore.Set(secrets.PemKey(pemFilepath), string(keyData)); err != nil {
+			return err
+		}
+	}
+	if len(passphrase) > 0 {
+		if err := secretStore.Set(secrets.PassphraseKey(pemFilepath), passphrase); err != nil {
+			return err
+		}
+	}
+
+	// configs with `IdentitiesOnly yes` only offer agent keys that match an IdentityFile. When the PEM key only lives
+	// in the secret store, ssh can still match the agent key using the public key next to the pem_filepath.
+	if options.SecretStore && !utils.FileExists(pemFilepath) {
+		if err := writePublicKey(pemFilepath, privateKeyData); err != nil {
+			return err
+		}
+	}
+
+	color.Green("Imported %v into the %v (pem_filepath: %v)", keyFilepath, secretStore.Name(), pemFilepath)
+	return nil
+}
+
+// Generate creates a new (unencrypted) ed25519 keypair at the templated pem_filepath of the config, and prints the
+// public key so it can be added to the bastion's authorized_keys.
+func (e *KeyAction) Generate(answerData map[string]interface{}, force bool) error {
+	pemFilepath, err := e.pemFilepath(answerData)
+	if err != nil {
+		return err
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	keyData := pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: marshalED25519PrivateKey(publicKey, privateKey, fmt.Sprintf("drawbridge %v", filepath.Base(pemFilepath))),
+	})
+
+	if err := writePemKey(pemFilepath, keyData, force); err != nil {
+		return err
+	}
+	if err := writePublicKey(pemFilepath, privateKey); err != nil {
+		return err
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	color.Green("Generated ed25519 keypair at %v", pemFilepath)
+	fmt.Printf("Add the following public key to ~/.ssh/authorized_keys on the bastion:\n\n%v", string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	return nil
+}
+
+func (e *KeyAction) pemFilepath(answerData map[string]interface{}) (string, error) {
+	_, pemFilepath, err := renderedFilepaths(e.Config, answerData)
+	if err != nil {
+		return "", err
+	} else if len(pemFilepath) == 0 {
+		return "", errors.ConfigValidationError("the active config template does not define a pem_filepath")
+	}
+	return utils.ExpandPath(pemFilepath)
+}
+
+// returns the public key of a PEM key, and whether it is encrypted. The public key of encrypted keys is read from the
+// OpenSSH key format, or from the `.pub` file next to the pem_filepath. It may be nil if neither is available.
+func pemPublicKey(pemFilepath string, keyData []byte) (ssh.PublicKey, bool, error) {
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err == nil {
+		return signer.PublicKey(), false, nil
+	}
+	passphraseErr, encrypted := err.(*ssh.PassphraseMissingError)
+	if !encrypted {
+		return nil, false, err
+	}
+
+	if passphraseErr.PublicKey != nil {
+		return passphraseErr.PublicKey, true, nil
+	}
+	if publicKeyData, err := ioutil.ReadFile(pemFilepath + ".pub"); err == nil {
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey(publicKeyData)
+		return publicKey, true, err
+	}
+	return nil, true, nil
+}
+
+// writes the PEM key with 0600 permissions (ssh refuses to use keys that are readable by others), refusing to replace
+// a different key unless force is true.
+func writePemKey(pemFilepath string, keyData []byte, force bool) error {
+	if existingKeyData, err := ioutil.ReadFile(pemFilepath); err == nil && !force && !bytes.Equal(existingKeyData, keyData) {
+		return errors.InvalidArgumentsError(fmt.Sprintf("a different PEM key already exists at %v, use --force to overwrite it", pemFilepath))
+	}
+	if err := os.MkdirAll(filepath.Dir(pemFilepath), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(pemFilepath, keyData, 0600); err != nil {
+		return err
+	}
+	// WriteFile does not change the permissions of existing files.
+	return os.Chmod(pemFilepath, 0600)
+}
+
+func writePublicKey(pemFilepath string, privateKeyData interface{}) error {
+	signer, err := ssh.NewSignerFromKey(privateKeyData)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(pemFilepath), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(pemFilepath+".pub", ssh.MarshalAuthorizedKey(signer.PublicKey()), 0644)
+}
+
+// encodes an unencrypted ed25519 key in the `openssh-key-v1` format used by ssh-keygen.
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func marshalED25519PrivateKey(publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey, comment string) []byte {
+	checkBytes := make([]byte, 4)
+	rand.Read(checkBytes)
+	check := binary.BigEndian.Uint32(checkBytes)
+
+	sshPublicKey := ssh.Marshal(struct {
+		KeyType string
+		Key     []byte
+	}{ssh.KeyAlgoED25519, publicKey})
+
+	privateBlock := ssh.Marshal(struct {
+		Check1     uint32
+		Check2     uint32
+		KeyType    string
+		PublicKey  []byte
+		PrivateKey []byte
+		Comment    string
+	}{check, check, ssh.KeyAlgoED25519, publicKey, privateKey, comment})
+	// pad the private block to the cipher block size (8 for "none")
+	for i := 1; len(privateBlock)%8 != 0; i++ {
+		privateBlock = append(privateBlock, byte(i))
+	}
+
+	return append([]byte("openssh-key-v1\x00"), ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PublicKey    []byte
+		PrivateBlock []byte
+	}{"none", "none", "", 1, sshPublicKey, privateBlock})...)
+}
@@ -0,0 +1,164 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/secrets"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyAction_Import_SecretStore(t *testing.T) {
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", filepath.Join(parentPath, "pem"))
+	configData.Set("options.secret_store", secrets.StoreFile)
+	os.Setenv(secrets.PasswordEnvVar, "master password")
+	defer os.Unsetenv(secrets.PasswordEnvVar)
+
+	keyAction := actions.KeyAction{Config: configData}
+	answerData := map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}
+	pemFilepath := filepath.Join(parentPath, "pem", "test", "aws-test.pem")
+
+	//test
+	err = keyAction.Import(answerData, filepath.Join("testdata", "connect", "test_rsa.pem"), actions.KeyImportOptions{SecretStore: true})
+	passphraseOnlyErr := keyAction.Import(answerData, filepath.Join("testdata", "connect", "test_rsa.pem"), actions.KeyImportOptions{PassphraseOnly: true})
+
+	//assert
+	require.NoError(t, err, "should import the PEM key into the secret store")
+	secretStore, err := secrets.New(configData)
+	require.NoError(t, err)
+	keyData, found, err := secretStore.Get(secrets.PemKey(pemFilepath))
+	require.NoError(t, err)
+	require.True(t, found, "should store the PEM key under the templated pem_filepath")
+	expectedKeyData, err := ioutil.ReadFile(filepath.Join("testdata", "connect", "test_rsa.pem"))
+	require.NoError(t, err)
+	require.Equal(t, string(expectedKeyData), keyData)
+	require.FileExists(t, pemFilepath+".pub", "should write the public key so IdentitiesOnly configs can match the agent key")
+	require.NoFileExists(t, pemFilepath, "should not write the private key to disk")
+	require.Error(t, passphraseOnlyErr, "should raise an error when storing the passphrase of an unencrypted key")
+}
+
+func TestKeyAction_Import(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", filepath.Join(parentPath, "pem"))
+	configData.Set("options.secret_store", secrets.StoreNone)
+
+	keyAction := actions.KeyAction{Config: configData}
+	answerData := map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}
+	pemFilepath := filepath.Join(parentPath, "pem", "test", "aws-test.pem")
+
+	//test
+	err = keyAction.Import(answerData, filepath.Join("testdata", "connect", "test_rsa.pem"), actions.KeyImportOptions{})
+	require.NoError(t, err, "should copy the PEM key into the pem_dir")
+	require.NoError(t, ioutil.WriteFile(pemFilepath, []byte("different key"), 0644))
+	overwriteErr := keyAction.Import(answerData, filepath.Join("testdata", "connect", "test_rsa.pem"), actions.KeyImportOptions{})
+	forceErr := keyAction.Import(answerData, filepath.Join("testdata", "connect", "test_rsa.pem"), actions.KeyImportOptions{Force: true})
+
+	//assert
+	require.Error(t, overwriteErr, "should not overwrite a different PEM key without force")
+	require.NoError(t, forceErr)
+	expectedKeyData, err := ioutil.ReadFile(filepath.Join("testdata", "connect", "test_rsa.pem"))
+	require.NoError(t, err)
+	keyData, err := ioutil.ReadFile(pemFilepath)
+	require.NoError(t, err)
+	require.Equal(t, expectedKeyData, keyData)
+	info, err := os.Stat(pemFilepath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "PEM keys should only be readable by the user")
+}
+
+func TestKeyAction_Generate_List(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("options.secret_store", secrets.StoreNone)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	for _, shardType := range []string{"live", "idle"} {
+		require.NoError(t, createAction.Start(map[string]interface{}{
+			"environment": "test",
+			"stack_name":  "app",
+			"shard":       "us-east-1",
+			"shard_type":  shardType,
+			"username":    "aws",
+		}, false))
+	}
+	keyAction := actions.KeyAction{Config: configData}
+
+	//test
+	missingKeys, err := keyAction.Keys()
+	require.NoError(t, err)
+	err = keyAction.Generate(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, false)
+	require.NoError(t, err)
+	presentKeys, err := keyAction.Keys()
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, 1, len(missingKeys), "configs that share a PEM key should be grouped")
+	require.Equal(t, []string{"1", "2"}, missingKeys[0].Configs)
+	require.Equal(t, actions.KeyStatusMissing, missingKeys[0].Status)
+
+	keyData, err := ioutil.ReadFile(filepath.Join(parentPath, "test.pem"))
+	require.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey(keyData)
+	require.NoError(t, err, "should generate a valid OpenSSH private key")
+	require.Equal(t, ssh.KeyAlgoED25519, signer.PublicKey().Type())
+	require.FileExists(t, filepath.Join(parentPath, "test.pem.pub"))
+
+	require.Equal(t, 1, len(presentKeys))
+	require.Equal(t, actions.KeyStatusPresent, presentKeys[0].Status)
+	require.Equal(t, ssh.FingerprintSHA256(signer.PublicKey()), presentKeys[0].Fingerprint)
+	require.False(t, presentKeys[0].Encrypted)
+}
@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// PingAction checks that the bastions of drawbridge configs are reachable, without authenticating.
+type PingAction struct {
+	Config config.Interface
+}
+
+// PingResult is the outcome of the bastion pre-flight check for a single config.
+type PingResult struct {
+	ConfigFilepath            string `json:"config_filepath" yaml:"config_filepath"`
+	Alias                     string `json:"alias,omitempty" yaml:"alias,omitempty"`
+	transport.PreflightResult `yaml:",inline"`
+	Error                     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Start checks the bastion of each config in parallel, and prints the results. Returns a BastionUnreachableError if
+// any bastion could not be reached.
+func (e *PingAction) Start(answerDataList []map[string]interface{}, timeout time.Duration, outputFormat string) error {
+	results := e.Results(answerDataList, timeout)
+
+	failed := []string{}
+	for _, result := range results {
+		if len(result.Error) > 0 {
+			failed = append(failed, result.ConfigFilepath)
+		}
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		if err := utils.PrintStructured(os.Stdout, outputFormat, results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			name := result.ConfigFilepath
+			if len(result.Alias) > 0 {
+				name = fmt.Sprintf("%v (%v)", name, result.Alias)
+			}
+			if len(result.Error) > 0 {
+				fmt.Printf("%v %v\n\t%v\n", color.RedString("✘"), name, result.Error)
+			} else if len(result.Skipped) > 0 {
+				fmt.Printf("%v %v\n\tskipped: %v\n", color.YellowString("-"), name, result.Skipped)
+			} else {
+				fmt.Printf("%v %v\n\t%v (%v) %v in %vms\n", color.GreenString("✔"), name, result.Host, result.Address, result.Banner, result.LatencyMs)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.BastionUnreachableError(fmt.Sprintf("%v of %v bastions are unreachable: %v", len(failed), len(results), strings.Join(failed, ", ")))
+	}
+	return nil
+}
+
+// Results runs the bastion pre-flight check for every config in parallel. The results are in the same order as the
+// answers.
+func (e *PingAction) Results(answerDataList []map[string]interface{}, timeout time.Duration) []PingResult {
+	results := make([]PingResult, len(answerDataList))
+	var wg sync.WaitGroup
+	for ndx, answerData := range answerDataList {
+		wg.Add(1)
+		go func(ndx int, answerData map[string]interface{}) {
+			defer wg.Done()
+
+			configFilepath, _, err := renderedFilepaths(e.Config, answerData)
+			results[ndx].ConfigFilepath = configFilepath
+			results[ndx].Alias = project.PrimaryAlias(answerData)
+			if err == nil {
+				results[ndx].PreflightResult, err = bastionPreflight(configFilepath, timeout)
+			}
+			if err != nil {
+				results[ndx].Error = err.Error()
+			}
+		}(ndx, answerData)
+	}
+	wg.Wait()
+	return results
+}
+
+// BastionPreflight fails fast with a BastionUnreachableError when the bastion of the rendered config cannot be reached,
+// rather than leaving the user waiting on ssh's connection timeout. Disabled with `options.bastion_preflight: false`.
+func (e *ConnectAction) BastionPreflight(configFilepath string) error {
+	_, err := bastionPreflight(configFilepath, transport.DefaultPreflightTimeout)
+	return err
+}
+
+func bastionPreflight(configFilepath string, timeout time.Duration) (transport.PreflightResult, error) {
+	nativeTransport, err := transport.New(configFilepath)
+	if err != nil {
+		return transport.PreflightResult{}, err
+	}
+	return nativeTransport.Preflight(timeout)
+}
@@ -0,0 +1,65 @@
+package actions_test
+
+import (
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPingAction_Start(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_test\r\n"))
+			conn.Close()
+		}
+	}()
+
+	configData, err := config.Create()
+	require.NoError(t, err)
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+
+	reachableConfig := fmt.Sprintf("Host bastion\n  Hostname 127.0.0.1\n  Port %v\n", listener.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "test-app-live-us-east-1"), []byte(reachableConfig), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "test-app-live-eu-west-1"), []byte("Host bastion\n  Hostname bastion.invalid\n"), 0600))
+
+	answerDataList := []map[string]interface{}{
+		{"environment": "test", "stack_name": "app", "shard_type": "live", "shard": "us-east-1", "username": "aws"},
+		{"environment": "test", "stack_name": "app", "shard_type": "live", "shard": "eu-west-1", "username": "aws", "alias": "eu"},
+	}
+	pingAction := actions.PingAction{Config: configData}
+
+	//test
+	results := pingAction.Results(answerDataList, time.Second)
+	err = pingAction.Start(answerDataList[:1], time.Second, "json")
+	failedErr := pingAction.Start(answerDataList, time.Second, "")
+
+	//assert
+	require.Len(t, results, 2)
+	require.Empty(t, results[0].Error)
+	require.Equal(t, "SSH-2.0-OpenSSH_test", results[0].Banner)
+	require.Equal(t, "eu", results[1].Alias)
+	require.Contains(t, results[1].Error, "could not resolve")
+	require.NoError(t, err, "should not raise an error when all bastions are reachable")
+	require.IsType(t, errors.BastionUnreachableError(""), failedErr, "should raise an error when a bastion is unreachable")
+}
@@ -1,8 +1,23 @@
 package actions
 
-import "github.com/analogj/drawbridge/pkg/config"
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/proxy"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
 
 type ProxyAction struct {
+	ConnectAction
 	Config config.Interface
 }
 
@@ -21,3 +36,166 @@ func (e *ProxyAction) Start(answerDataList []map[string]interface{}, dryRun bool
 
 	return nil
 }
+
+// the destinations of a rendered PAC file: the domains sent to a proxy, and the proxy addresses.
+var pacDomainPattern = regexp.MustCompile(`dnsDomainIs\(\s*host\s*,\s*["']([^"']+)["']\s*\)`)
+var pacProxyPattern = regexp.MustCompile(`\b(?:PROXY|SOCKS[45]?|HTTPS?)\s+([^\s;"']+)`)
+
+// Serve runs a local SOCKS5/HTTP proxy which routes each destination domain through the bastion of the matching
+// config, and serves the rendered `pac_template` on http://<listenAddr>/proxy.pac
+func (e *ProxyAction) Serve(answerDataList []map[string]interface{}, listenAddr string) error {
+	pacTemplate, err := e.Config.GetPacTemplate()
+	if err != nil {
+		return err
+	}
+	pacContent, err := pacTemplate.Render(answerDataList)
+	if err != nil {
+		return err
+	}
+
+	routes, err := e.Routes(answerDataList)
+	if err != nil {
+		return err
+	}
+	if len(routes) == 0 {
+		return errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
+	}
+
+	// load the PEM keys up front, while we can still prompt for passphrases.
+	for _, answerData := range answerDataList {
+		if pemFilepath, ok := answerData["config"].(map[string]interface{})["pem_filepath"].(string); ok && len(pemFilepath) > 0 {
+			if err := e.SshAgentAddPemKey(pemFilepath, answerData); err != nil {
+				color.Yellow("WARNING: %v", err)
+			}
+		}
+	}
+
+	server := &proxy.Server{
+		ListenAddr: listenAddr,
+		Pac:        pacContent,
+		Routes:     routes,
+		Dial:       e.dialer(),
+	}
+
+	for _, route := range routes {
+		fmt.Printf("%v %v -> %v\n", color.GreenString(strings.Join(route.Domains, ", ")), color.YellowString("[%v]", strings.Join(route.ListenAddrs, ", ")), route.ConfigFilepath)
+	}
+	color.HiBlue("Proxy listening on %v (SOCKS5 & HTTP). PAC file available at http://%v/proxy.pac", listenAddr, listenAddr)
+
+	return server.ListenAndServe()
+}
+
+// Routes renders the `pac_template` for each answer on its own, and maps the domains it sends to a proxy (and the proxy
+// addresses it points at) to the config's ssh config file. Routing always matches the PAC file served by `proxy serve`.
+func (e *ProxyAction) Routes(answerDataList []map[string]interface{}) ([]proxy.Route, error) {
+	pacTemplate, err := e.Config.GetPacTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []proxy.Route{}
+	for _, answerData := range answerDataList {
+		configData, configOk := answerData["config"].(map[string]interface{})
+		if !configOk {
+			continue
+		}
+		pacContent, err := pacTemplate.Render([]map[string]interface{}{answerData})
+		if err != nil {
+			return nil, err
+		}
+
+		route := proxy.Route{
+			Domains:        []string{},
+			ListenAddrs:    []string{},
+			ConfigFilepath: configData["filepath"].(string),
+		}
+		for _, match := range pacDomainPattern.FindAllStringSubmatch(pacContent, -1) {
+			if !utils.SliceIncludes(route.Domains, match[1]) {
+				route.Domains = append(route.Domains, match[1])
+			}
+		}
+		for _, match := range pacProxyPattern.FindAllStringSubmatch(pacContent, -1) {
+			if !utils.SliceIncludes(route.ListenAddrs, match[1]) {
+				route.ListenAddrs = append(route.ListenAddrs, match[1])
+			}
+		}
+		if len(route.Domains) == 0 && len(route.ListenAddrs) == 0 {
+			log.Debugf("`pac_template` does not route any domains through %v", route.ConfigFilepath)
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func (e *ProxyAction) dialer() proxy.Dialer {
+	if e.Config.GetString("options.transport") != "native" {
+		return func(route proxy.Route, addr string) (io.ReadWriteCloser, error) {
+			return transport.ExecDial(route.ConfigFilepath, transport.BastionHostAlias, addr)
+		}
+	}
+
+	// with the native transport a single bastion connection per config is shared by all proxied requests. The mutex
+	// only guards the map, so a slow bastion does not hold up requests for the other configs.
+	connections := map[string]*bastionConnection{}
+	var mutex sync.Mutex
+
+	// forget a failed bastion connection, unless another request already replaced it.
+	drop := func(configFilepath string, bastion *bastionConnection) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if connections[configFilepath] == bastion {
+			delete(connections, configFilepath)
+			if bastion.connection != nil {
+				bastion.connection.Close()
+			}
+		}
+	}
+
+	return func(route proxy.Route, addr string) (io.ReadWriteCloser, error) {
+		for attempt := 0; ; attempt++ {
+			mutex.Lock()
+			bastion, cached := connections[route.ConfigFilepath]
+			if !cached {
+				bastion = &bastionConnection{}
+				connections[route.ConfigFilepath] = bastion
+			}
+			mutex.Unlock()
+
+			connection, err := bastion.get(route.ConfigFilepath)
+			if err != nil {
+				drop(route.ConfigFilepath, bastion)
+				return nil, err
+			}
+			conn, err := connection.Dial("tcp", addr)
+			if err == nil {
+				return conn, nil
+			}
+			// only a cached connection may have dropped since it was opened, reconnect once.
+			if !cached || attempt > 0 {
+				return nil, err
+			}
+			log.Debugf("Bastion connection for %v failed, reconnecting: %v", route.ConfigFilepath, err)
+			drop(route.ConfigFilepath, bastion)
+		}
+	}
+}
+
+// bastionConnection dials the bastion of a config once, concurrent callers wait for the same connection.
+type bastionConnection struct {
+	once       sync.Once
+	connection *transport.Connection
+	err        error
+}
+
+func (b *bastionConnection) get(configFilepath string) (*transport.Connection, error) {
+	b.once.Do(func() {
+		nativeTransport, err := transport.New(configFilepath)
+		if err != nil {
+			b.err = err
+			return
+		}
+		b.connection, b.err = nativeTransport.Dial(transport.BastionHostAlias)
+	})
+	return b.connection, b.err
+}
@@ -1,6 +1,7 @@
 package actions_test
 
 import (
+	"fmt"
 	"github.com/analogj/drawbridge/pkg/actions"
 	"github.com/analogj/drawbridge/pkg/config"
 	"github.com/analogj/drawbridge/pkg/utils"
@@ -53,3 +54,38 @@ func TestProxyAction_Start(t *testing.T) {
 	require.NoError(t, err, "should not raise an error when generating pac file")
 	require.FileExists(t, filepath.Join(parentPath, "drawbridge.pac"))
 }
+
+func TestProxyAction_Routes(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	proxyAction := actions.ProxyAction{
+		Config: configData,
+	}
+
+	//test
+	routes, err := proxyAction.Routes([]map[string]interface{}{
+		{
+			"environment": "test",
+			"stack_name":  "app",
+			"shard":       "us-east-1",
+			"shard_type":  "idle",
+			"username":    "aws",
+			"config": map[string]interface{}{
+				"filepath": "/tmp/drawbridge/test-app-idle-us-east-1",
+			},
+		},
+	})
+
+	//assert
+	require.NoError(t, err, "should not raise an error when rendering proxy routes")
+	uniquePort, err := utils.UniquePort("/tmp/drawbridge/test-app-idle-us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	require.Equal(t, []string{".internal.idle.us-east-1.apptestexample.com"}, routes[0].Domains, "should route the domains of the pac_template")
+	require.Equal(t, []string{fmt.Sprintf("localhost:%v", uniquePort)}, routes[0].ListenAddrs, "should listen on the proxy address of the pac_template")
+	require.Equal(t, "/tmp/drawbridge/test-app-idle-us-east-1", routes[0].ConfigFilepath)
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/recording"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// runs the interactive shell while recording its output to an asciicast file under `<config_dir>/recordings`. Only the
+// native transport exposes the output of the session, so recorded sessions always use it.
+func (e *ConnectAction) recordShell(nativeTransport *transport.NativeTransport, configHost string, answerData map[string]interface{}, auditEntry *audit.Entry) error {
+	recordingsDir, err := recording.Dir(e.Config)
+	if err != nil {
+		return err
+	}
+	configFilepath, _, err := renderedFilepaths(e.Config, answerData)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(configFilepath)
+	if alias := project.PrimaryAlias(answerData); len(alias) > 0 {
+		name = alias
+	}
+	host := auditEntry.Host
+	if len(host) == 0 {
+		host = "bastion"
+	}
+
+	startedAt := time.Now()
+	recordingFilepath := recording.NewFilepath(recordingsDir, name, host, startedAt)
+	if err := os.MkdirAll(filepath.Dir(recordingFilepath), 0700); err != nil {
+		return err
+	}
+	recordingFile, err := os.OpenFile(recordingFilepath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer recordingFile.Close()
+
+	width, height, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	recorder, err := recording.NewRecorder(recordingFile, recording.Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: startedAt.Unix(),
+		Title:     fmt.Sprintf("%v → %v", name, host),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
+	auditEntry.Recording = recordingFilepath
+	color.Cyan("Recording session to %v", recordingFilepath)
+	nativeTransport.Stdout = io.MultiWriter(nativeTransport.Stdout, recorder)
+	nativeTransport.Stderr = io.MultiWriter(nativeTransport.Stderr, recorder)
+	return nativeTransport.Shell(configHost)
+}
@@ -0,0 +1,229 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type RegenerateAction struct {
+	Config config.Interface
+}
+
+// RenderedFile is a config/custom template rendered in memory from a saved answers file.
+type RenderedFile struct {
+	FilePath string `json:"filepath" yaml:"filepath"`
+	Content  string `json:"content" yaml:"content"`
+
+	// set when the templated filepath no longer matches the path saved in the answers file.
+	PreviousFilePath string `json:"previous_filepath,omitempty" yaml:"previous_filepath,omitempty"`
+}
+
+// RenderedProject contains everything that would be written by `drawbridge create` for a saved answers file
+type RenderedProject struct {
+	Files   []RenderedFile         `json:"files" yaml:"files"`
+	Answers map[string]interface{} `json:"answers" yaml:"answers"`
+
+	AnswersFilePath         string `json:"answers_filepath" yaml:"answers_filepath"`
+	PreviousAnswersFilePath string `json:"-" yaml:"-"`
+}
+
+// Start re-renders the config template & active custom templates for each saved answers file, printing a diff and
+// only writing files whose content changed.
+func (e *RegenerateAction) Start(answerDataList []map[string]interface{}, dryRun bool) error {
+	for _, answerData := range answerDataList {
+		err := e.One(answerData, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	// configs may have been moved, or the index may not exist yet (created with an older version of drawbridge)
+	indexAction := IndexAction{Config: e.Config}
+	return indexAction.Start(dryRun)
+}
+
+func (e *RegenerateAction) One(answerData map[string]interface{}, dryRun bool) error {
+	log.Debugf("Answer Data: %v", answerData)
+
+	rendered, err := e.Render(answerData)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, renderedFile := range rendered.Files {
+		previousFilePath := renderedFile.FilePath
+		if len(renderedFile.PreviousFilePath) > 0 {
+			previousFilePath = renderedFile.PreviousFilePath
+		}
+
+		previousContent := ""
+		if utils.FileExists(previousFilePath) {
+			previousContentBytes, err := ioutil.ReadFile(previousFilePath)
+			if err != nil {
+				return err
+			}
+			previousContent = string(previousContentBytes)
+		}
+
+		if previousContent == renderedFile.Content && previousFilePath == renderedFile.FilePath {
+			log.Debugf("%v is up to date", renderedFile.FilePath)
+			continue
+		}
+
+		changed = true
+		utils.PrintDiff(previousFilePath, previousContent, renderedFile.FilePath, renderedFile.Content)
+		if err := e.writeFile(renderedFile, 0644, dryRun); err != nil {
+			return err
+		}
+	}
+
+	// persist the (possibly moved) config & custom filepaths in the answers file.
+	previousAnswers, _ := ioutil.ReadFile(rendered.PreviousAnswersFilePath)
+	answersContent, err := yaml.Marshal(rendered.Answers)
+	if err != nil {
+		return err
+	}
+	if string(previousAnswers) != string(answersContent) || rendered.PreviousAnswersFilePath != rendered.AnswersFilePath {
+		changed = true
+		fmt.Printf("Updating answers file: %v\n", rendered.AnswersFilePath)
+		err = e.writeFile(RenderedFile{
+			FilePath:         rendered.AnswersFilePath,
+			Content:          string(answersContent),
+			PreviousFilePath: rendered.PreviousAnswersFilePath,
+		}, 0640, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !changed {
+		color.Green("%v is up to date", answerData["config"].(map[string]interface{})["filepath"])
+	}
+	return nil
+}
+
+// Render re-renders all templates for a saved answers file in memory, using the current drawbridge.yaml
+func (e *RegenerateAction) Render(answerData map[string]interface{}) (RenderedProject, error) {
+	storedConfig, _ := answerData["config"].(map[string]interface{})
+	storedCustom, _ := answerData["custom"].([]interface{})
+
+	renderData, err := utils.MapDeepCopy(answerData)
+	if err != nil {
+		return RenderedProject{}, err
+	}
+	delete(renderData, "config")
+	delete(renderData, "custom")
+	delete(renderData, "template")
+
+	// templates may be selected per answers file (active_config_template & active_custom_templates are stored answers).
+	// The config is shared by every answers file, so the options are restored once this one is rendered.
+	previousOptions := map[string]interface{}{}
+	if err := e.Config.UnmarshalKey("options", &previousOptions); err != nil {
+		return RenderedProject{}, err
+	}
+	defer e.Config.Set("options", previousOptions)
+	e.Config.SetOptionsFromAnswers(renderData)
+
+	rendered := RenderedProject{Files: []RenderedFile{}}
+
+	activeConfigTemplate, err := e.Config.GetActiveConfigTemplate()
+	if err != nil {
+		return rendered, err
+	}
+	configFilePath, configContent, configTemplateData, err := activeConfigTemplate.RenderTemplate(renderData, e.Config.InternalQuestionKeys())
+	if err != nil {
+		return rendered, err
+	}
+	rendered.Files = append(rendered.Files, RenderedFile{
+		FilePath:         configFilePath,
+		Content:          configContent,
+		PreviousFilePath: movedFilePath(storedConfig, configFilePath),
+	})
+	renderData["config"] = configTemplateData
+
+	activeCustomTemplates, err := e.Config.GetActiveCustomTemplates()
+	if err != nil {
+		return rendered, err
+	}
+	renderData["custom"] = []interface{}{}
+	for ndx, customTemplate := range activeCustomTemplates {
+		customFilePath, customContent, customTemplateData, err := customTemplate.RenderTemplate(renderData)
+		if err != nil {
+			return rendered, err
+		}
+
+		var storedCustomData map[string]interface{}
+		if ndx < len(storedCustom) {
+			storedCustomData, _ = storedCustom[ndx].(map[string]interface{})
+		}
+		rendered.Files = append(rendered.Files, RenderedFile{
+			FilePath:         customFilePath,
+			Content:          customContent,
+			PreviousFilePath: movedFilePath(storedCustomData, customFilePath),
+		})
+		renderData["custom"] = append(renderData["custom"].([]interface{}), customTemplateData)
+	}
+
+	rendered.Answers = renderData
+	rendered.AnswersFilePath = answersFilePathFor(renderData)
+	rendered.PreviousAnswersFilePath = answersFilePathFor(answerData)
+	return rendered, nil
+}
+
+func (e *RegenerateAction) writeFile(renderedFile RenderedFile, perm os.FileMode, dryRun bool) error {
+	moved := len(renderedFile.PreviousFilePath) > 0 && renderedFile.PreviousFilePath != renderedFile.FilePath
+	if moved {
+		fmt.Printf("Moving %v to %v\n", renderedFile.PreviousFilePath, renderedFile.FilePath)
+	}
+
+	if !dryRun {
+		//make the file's parent directory.
+		err := os.MkdirAll(filepath.Dir(renderedFile.FilePath), 0777)
+		if err != nil {
+			return err
+		}
+	}
+	err := utils.FileWrite(renderedFile.FilePath, renderedFile.Content, perm, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if moved && !dryRun && utils.FileExists(renderedFile.PreviousFilePath) {
+		return utils.FileDelete(renderedFile.PreviousFilePath)
+	}
+	return nil
+}
+
+// returns the stored filepath from the template data if it differs from the newly rendered filepath.
+func movedFilePath(storedTemplateData map[string]interface{}, renderedFilePath string) string {
+	if storedTemplateData == nil {
+		return ""
+	}
+	storedFilePath, ok := storedTemplateData["filepath"].(string)
+	if !ok || storedFilePath == renderedFilePath {
+		return ""
+	}
+	return storedFilePath
+}
+
+// answers files are stored next to the config file they were rendered with, see CreateAction.WriteAnswersFile
+func answersFilePathFor(answerData map[string]interface{}) string {
+	configData, ok := answerData["config"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	configDir, err := utils.ExpandPath(answerData["config_dir"].(string))
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, fmt.Sprintf(".%v.answers.yaml", filepath.Base(configData["filepath"].(string))))
+}
@@ -0,0 +1,149 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegenerateAction_Start(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	createAction := actions.CreateAction{Config: configData}
+	err = createAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, false)
+	require.NoError(t, err)
+
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.stack_name}}-{{.shard_type}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n  Port 2222\n",
+		},
+	})
+	projectList, err := project.CreateProjectListFromConfigDir(configData)
+	require.NoError(t, err)
+	regenerateAction := actions.RegenerateAction{Config: configData}
+
+	//test
+	err = regenerateAction.Start(projectList.GetAll(), false)
+
+	//assert
+	require.NoError(t, err, "should not raise an error when regenerating configs")
+	content, err := ioutil.ReadFile(filepath.Join(parentPath, "test-app-live-us-east-1"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "Port 2222", "should re-render the config file with the updated template")
+}
+
+func TestRegenerateAction_Render_MovedFile(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath":     "{{.environment}}-{{.shard}}",
+			"pem_filepath": "{{.environment}}.pem",
+			"content":      "Host bastion\n  User {{.username}}\n",
+		},
+	})
+	regenerateAction := actions.RegenerateAction{Config: configData}
+
+	//test
+	rendered, err := regenerateAction.Render(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+		"config_dir":  parentPath,
+		"pem_dir":     parentPath,
+		"config": map[string]interface{}{
+			"filepath": filepath.Join(parentPath, "test-app-live-us-east-1"),
+		},
+	})
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(parentPath, "test-us-east-1"), rendered.Files[0].FilePath)
+	require.Equal(t, filepath.Join(parentPath, "test-app-live-us-east-1"), rendered.Files[0].PreviousFilePath, "should detect that the config filepath changed")
+	require.Equal(t, filepath.Join(parentPath, ".test-us-east-1.answers.yaml"), rendered.AnswersFilePath)
+	require.Equal(t, filepath.Join(parentPath, ".test-app-live-us-east-1.answers.yaml"), rendered.PreviousAnswersFilePath)
+}
+
+func TestRegenerateAction_Render_RestoresOptions(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("options.active_config_template", "default")
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath": "{{.environment}}-default",
+			"content":  "Host bastion\n",
+		},
+		"alternative": map[string]interface{}{
+			"filepath": "{{.environment}}-alternative",
+			"content":  "Host bastion\n",
+		},
+	})
+	regenerateAction := actions.RegenerateAction{Config: configData}
+
+	//test
+	alternative, alternativeErr := regenerateAction.Render(map[string]interface{}{
+		"environment":            "test",
+		"config_dir":             parentPath,
+		"active_config_template": "alternative",
+	})
+	standard, standardErr := regenerateAction.Render(map[string]interface{}{
+		"environment": "prod",
+		"config_dir":  parentPath,
+	})
+
+	//assert
+	require.NoError(t, alternativeErr)
+	require.NoError(t, standardErr)
+	require.Equal(t, filepath.Join(parentPath, "test-alternative"), alternative.Files[0].FilePath)
+	require.Equal(t, filepath.Join(parentPath, "prod-default"), standard.Files[0].FilePath, "should not use the options of the previously rendered answers")
+	require.Equal(t, "default", configData.GetString("options.active_config_template"))
+}
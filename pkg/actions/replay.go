@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/recording"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// ReplayAction lists & plays back the session recordings made by `drawbridge connect --record`.
+type ReplayAction struct {
+	Config config.Interface
+}
+
+// Recordings returns the session recordings, oldest first.
+func (e *ReplayAction) Recordings() ([]recording.Recording, error) {
+	recordingsDir, err := recording.Dir(e.Config)
+	if err != nil {
+		return nil, err
+	}
+	return recording.List(recordingsDir)
+}
+
+// List prints the session recordings, numbered for `drawbridge replay [recording_number]`.
+func (e *ReplayAction) List(outputFormat string) error {
+	recordings, err := e.Recordings()
+	if err != nil {
+		return err
+	}
+
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, recordings)
+	}
+
+	if len(recordings) == 0 {
+		fmt.Println("No session recordings found, record a session with `drawbridge connect --record`")
+		return nil
+	}
+	for ndx, sessionRecording := range recordings {
+		fmt.Printf("%d: %v %v → %v\n", ndx+1, sessionRecording.StartedAt.Format("2006-01-02 15:04:05"), sessionRecording.Name, sessionRecording.Host)
+	}
+	return nil
+}
+
+// Recording finds a recording by its number in the list, its filepath, or the config alias/name (the most recent
+// recording is used).
+func (e *ReplayAction) Recording(selector string) (string, error) {
+	if info, err := os.Stat(selector); err == nil && !info.IsDir() {
+		return selector, nil
+	}
+
+	recordings, err := e.Recordings()
+	if err != nil {
+		return "", err
+	}
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 1 || index > len(recordings) {
+			return "", errors.InvalidArgumentsError(fmt.Sprintf("there is no recording number %v, see `drawbridge replay`", index))
+		}
+		return recordings[index-1].Filepath, nil
+	}
+	for ndx := len(recordings) - 1; ndx >= 0; ndx-- {
+		if recordings[ndx].Name == selector {
+			return recordings[ndx].Filepath, nil
+		}
+	}
+	return "", errors.InvalidArgumentsError(fmt.Sprintf("no recording found for `%v`, see `drawbridge replay`", selector))
+}
+
+// Start plays back a recording in the terminal. Pauses longer than idleLimit (if positive) are shortened.
+func (e *ReplayAction) Start(selector string, speed float64, idleLimit time.Duration) error {
+	recordingFilepath, err := e.Recording(selector)
+	if err != nil {
+		return err
+	}
+	recordingFile, err := os.Open(recordingFilepath)
+	if err != nil {
+		return err
+	}
+	defer recordingFile.Close()
+
+	return recording.Play(recordingFile, os.Stdout, speed, idleLimit)
+}
@@ -0,0 +1,62 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/recording"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplayAction_Recording(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+
+	recordingsDir := filepath.Join(parentPath, "recordings")
+	startedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	recordingFilepaths := []string{
+		recording.NewFilepath(recordingsDir, "prod", "database-1", startedAt.Add(time.Hour)),
+		recording.NewFilepath(recordingsDir, "prod", "bastion", startedAt),
+		recording.NewFilepath(recordingsDir, "test-app-live-us-east-1", "bastion", startedAt.Add(2*time.Hour)),
+	}
+	for _, recordingFilepath := range recordingFilepaths {
+		require.NoError(t, os.MkdirAll(filepath.Dir(recordingFilepath), 0700))
+		require.NoError(t, ioutil.WriteFile(recordingFilepath, []byte("{\"version\": 2, \"width\": 80, \"height\": 24}\n[0.1, \"o\", \"$ \"]\n"), 0600))
+	}
+	replayAction := actions.ReplayAction{Config: configData}
+
+	//test
+	recordings, err := replayAction.Recordings()
+	require.NoError(t, err)
+	byIndex, indexErr := replayAction.Recording("1")
+	byAlias, aliasErr := replayAction.Recording("prod")
+	byFilepath, filepathErr := replayAction.Recording(recordingFilepaths[2])
+	_, missingErr := replayAction.Recording("4")
+	playErr := replayAction.Start("prod", 1, 0)
+
+	//assert
+	require.Len(t, recordings, 3)
+	require.Equal(t, "prod", recordings[0].Name)
+	require.Equal(t, "bastion", recordings[0].Host)
+	require.Equal(t, startedAt, recordings[0].StartedAt)
+	require.NoError(t, indexErr)
+	require.Equal(t, recordingFilepaths[1], byIndex, "should number recordings oldest first")
+	require.NoError(t, aliasErr)
+	require.Equal(t, recordingFilepaths[0], byAlias, "should pick the most recent recording for an alias")
+	require.NoError(t, filepathErr)
+	require.Equal(t, recordingFilepaths[2], byFilepath)
+	require.IsType(t, errors.InvalidArgumentsError(""), missingErr)
+	require.NoError(t, playErr)
+}
@@ -0,0 +1,453 @@
+package actions
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const tunnelMinBackoff = 1 * time.Second
+const tunnelMaxBackoff = 60 * time.Second
+
+// a connection that stays up this long is considered healthy, and resets the reconnect backoff.
+const tunnelStableDuration = 60 * time.Second
+
+// printed by the ssh LocalCommand once the exec transport tunnel is connected.
+const tunnelConnectedMarker = "drawbridge-tunnel-connected"
+
+type TunnelAction struct {
+	ConnectAction
+	Config config.Interface
+}
+
+// TunnelState is persisted to `<config_dir>/tunnels/<config id>.yaml` by the background tunnel process. Keying by the
+// config ID keeps configs with the same file name (in different sub directories of config_dir) apart.
+type TunnelState struct {
+	Pid            int       `json:"pid" yaml:"pid"`
+	ID             string    `json:"id" yaml:"id"`
+	ConfigFilepath string    `json:"config_filepath" yaml:"config_filepath"`
+	Alias          string    `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Forwards       []string  `json:"forwards" yaml:"forwards"`
+	Status         string    `json:"status" yaml:"status"`
+	Reconnects     int       `json:"reconnects" yaml:"reconnects"`
+	LastError      string    `json:"last_error,omitempty" yaml:"last_error,omitempty"`
+	StartedAt      time.Time `json:"started_at" yaml:"started_at"`
+	UpdatedAt      time.Time `json:"updated_at" yaml:"updated_at"`
+	LogFilepath    string    `json:"log_filepath" yaml:"log_filepath"`
+}
+
+// Start launches a background `drawbridge tunnel run` process for each config.
+// PEM keys are added to the ssh-agent here, while we still have a terminal to prompt for passphrases.
+func (e *TunnelAction) Start(answerDataList []map[string]interface{}) error {
+	tunnelDir, err := e.tunnelDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tunnelDir, 0700); err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	for _, answerData := range answerDataList {
+		configFilepath, pemFilepath, err := renderedFilepaths(e.Config, answerData)
+		if err != nil {
+			return err
+		}
+
+		id := tunnelID(answerData, configFilepath)
+		if state, err := e.readState(id); err == nil && processAlive(state.Pid) {
+			color.Yellow("Tunnel for %v is already running (pid %v). Skipping", configFilepath, state.Pid)
+			continue
+		}
+
+		if len(pemFilepath) > 0 {
+			if err := e.SshAgentAddPemKey(pemFilepath, answerData); err != nil {
+				return err
+			}
+		}
+
+		logFilepath := e.logFilepath(id)
+		logFile, err := os.OpenFile(logFilepath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+
+		runArgs := []string{"tunnel", "run", "--id", id}
+		if alias := project.PrimaryAlias(answerData); len(alias) > 0 {
+			runArgs = append(runArgs, "--alias", alias)
+		}
+		cmd := exec.Command(executable, append(runArgs, configFilepath)...)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		cmd.Env = environWithoutPrivateAgent()
+		forwardAgentKey := privateAgentRunning() && len(pemFilepath) > 0
+		if forwardAgentKey {
+			cmd.Env = append(cmd.Env, privateAgentKeyEnvVar+"=true")
+		}
+		cmd.SysProcAttr = detachedProcAttr()
+		err = cmd.Start()
+		logFile.Close()
+		if err != nil {
+			return err
+		}
+
+		// the tunnel process outlives our private ssh-agent, so it serves its own. Hand the PEM key over to it.
+		if forwardAgentKey {
+			if err := forwardPrivateAgentKey(e.Config, cmd.Process.Pid, pemFilepath, 10*time.Second); err != nil {
+				color.Yellow("WARNING: Could not add the PEM key to the tunnel process: %v", err)
+			}
+		}
+
+		fmt.Printf("Started tunnel for %v (pid %v). Logs: %v\n", configFilepath, cmd.Process.Pid, logFilepath)
+		// the child process is intentionally never waited on, it outlives this command.
+		cmd.Process.Release()
+	}
+	return nil
+}
+
+// Run keeps the LocalForwards of the bastion host in configFilepath up, reconnecting with exponential backoff.
+// This runs in the foreground, and is normally invoked by Start as a detached background process. When id is empty, it
+// is derived from configFilepath.
+func (e *TunnelAction) Run(configFilepath string, id string, alias string) error {
+	if len(id) == 0 {
+		id = project.ConfigID(configFilepath)
+	}
+
+	config, err := sshconfig.ParseFile(configFilepath)
+	if err != nil {
+		return err
+	}
+
+	state := TunnelState{
+		Pid:            os.Getpid(),
+		ID:             id,
+		ConfigFilepath: configFilepath,
+		Alias:          alias,
+		Forwards:       config.GetAll(transport.BastionHostAlias, "localforward"),
+		Status:         "connecting",
+		StartedAt:      time.Now(),
+		LogFilepath:    e.logFilepath(id),
+	}
+	if len(state.Forwards) == 0 {
+		return errors.ConfigValidationError(fmt.Sprintf("no LocalForward entries defined for the bastion in %v", configFilepath))
+	}
+
+	tunnelDir, err := e.tunnelDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tunnelDir, 0700); err != nil {
+		return err
+	}
+
+	// without an ssh-agent, `tunnel start` adds the PEM key to a private ssh-agent served by this process.
+	if len(os.Getenv("SSH_AUTH_SOCK")) == 0 {
+		if _, err := startPrivateAgent(e.Config); err != nil {
+			return err
+		}
+		defer StopPrivateAgent()
+		if os.Getenv(privateAgentKeyEnvVar) == "true" && !waitForPrivateAgentKeys(10*time.Second) {
+			log.Warn("No PEM key was added to the private ssh-agent")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Info("Stopping tunnel")
+		cancel()
+	}()
+	defer os.Remove(e.stateFilepath(id))
+
+	backoff := tunnelMinBackoff
+	for {
+		state.Status = "connecting"
+		if err := e.writeState(state); err != nil {
+			return err
+		}
+
+		connectedAt := time.Now()
+		log.Infof("Opening tunnel for %v", configFilepath)
+		err := e.runOnce(ctx, configFilepath, func() {
+			connectedAt = time.Now()
+			state.Status = "running"
+			if err := e.writeState(state); err != nil {
+				log.Warnf("Could not write the tunnel state: %v", err)
+			}
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Since(connectedAt) > tunnelStableDuration {
+			backoff = tunnelMinBackoff
+		}
+
+		state.Reconnects++
+		state.Status = "reconnecting"
+		if err != nil {
+			state.LastError = err.Error()
+		}
+		log.Warnf("Tunnel dropped (%v). Reconnecting in %v", err, backoff)
+		if err := e.writeState(state); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > tunnelMaxBackoff {
+			backoff = tunnelMaxBackoff
+		}
+	}
+}
+
+// Status returns the state of all tunnels, and cleans up state files left behind by dead processes.
+func (e *TunnelAction) Status() ([]TunnelState, error) {
+	tunnelDir, err := e.tunnelDir()
+	if err != nil {
+		return nil, err
+	}
+	stateFiles, err := filepath.Glob(filepath.Join(tunnelDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	states := []TunnelState{}
+	for _, stateFile := range stateFiles {
+		state, err := readTunnelStateFile(stateFile)
+		if err != nil {
+			log.Debugf("Could not read tunnel state file %v: %v", stateFile, err)
+			continue
+		}
+		if !processAlive(state.Pid) {
+			state.Status = "dead"
+			os.Remove(stateFile)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (e *TunnelAction) PrintStatus(outputFormat string) error {
+	states, err := e.Status()
+	if err != nil {
+		return err
+	}
+	if utils.IsStructuredOutput(outputFormat) {
+		return utils.PrintStructured(os.Stdout, outputFormat, states)
+	}
+	if len(states) == 0 {
+		color.Yellow("No drawbridge tunnels are running")
+		return nil
+	}
+
+	for _, state := range states {
+		name := fmt.Sprintf("%v (%v)", filepath.Base(state.ConfigFilepath), state.ID)
+		if len(state.Alias) > 0 {
+			name = fmt.Sprintf("%v, %v", name, state.Alias)
+		}
+
+		statusColor := color.GreenString
+		if state.Status != "running" {
+			statusColor = color.YellowString
+		}
+		if state.Status == "dead" {
+			statusColor = color.RedString
+		}
+
+		fmt.Printf("%v [pid %v] %v, up %v, %v reconnects\n",
+			color.YellowString(name),
+			state.Pid,
+			statusColor(state.Status),
+			time.Since(state.StartedAt).Round(time.Second),
+			state.Reconnects,
+		)
+		for _, forward := range state.Forwards {
+			fmt.Printf("\tLocalForward %v\n", forward)
+		}
+		if len(state.LastError) > 0 {
+			fmt.Printf("\tlast error: %v\n", color.RedString(state.LastError))
+		}
+	}
+	return nil
+}
+
+// Stop terminates the tunnels for the provided configs, or every running tunnel if the list is empty.
+func (e *TunnelAction) Stop(answerDataList []map[string]interface{}) error {
+	states, err := e.Status()
+	if err != nil {
+		return err
+	}
+
+	ids := []string{}
+	for _, answerData := range answerDataList {
+		configFilepath, _, err := renderedFilepaths(e.Config, answerData)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, tunnelID(answerData, configFilepath))
+	}
+
+	for _, state := range states {
+		if len(ids) > 0 && !utils.SliceIncludes(ids, state.ID) {
+			continue
+		}
+		if state.Status == "dead" {
+			continue
+		}
+
+		fmt.Printf("Stopping tunnel for %v (pid %v)\n", state.ConfigFilepath, state.Pid)
+		if err := stopProcess(state.Pid); err != nil {
+			color.Red("ERROR IGNORED: %v", err)
+		}
+	}
+	return nil
+}
+
+// runOnce keeps the tunnel up until the connection drops. connected is called once the bastion connection and the
+// LocalForward listeners are up, before that the tunnel is only "connecting".
+func (e *TunnelAction) runOnce(ctx context.Context, configFilepath string, connected func()) error {
+	if e.Config.GetString("options.transport") == "native" {
+		nativeTransport, err := transport.New(configFilepath)
+		if err != nil {
+			return err
+		}
+		return nativeTransport.Forward(ctx, transport.BastionHostAlias, connected)
+	}
+
+	sshBin, lookErr := exec.LookPath("ssh")
+	if lookErr != nil {
+		return errors.DependencyMissingError("ssh is missing")
+	}
+
+	// ssh runs the LocalCommand once authenticated and the forwards are set up, which marks the tunnel as connected.
+	cmd := exec.CommandContext(ctx, sshBin, "-N", "-F", configFilepath,
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+		"-o", "BatchMode=yes",
+		"-o", "PermitLocalCommand=yes",
+		"-o", "LocalCommand=echo "+tunnelConnectedMarker,
+		transport.BastionHostAlias,
+	)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// all output must be read before waiting for the process
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if scanner.Text() == tunnelConnectedMarker {
+			connected()
+			continue
+		}
+		fmt.Println(scanner.Text())
+	}
+	return cmd.Wait()
+}
+
+// returns the rendered config & pem filepaths for the answers, using the active config template.
+func renderedFilepaths(appConfig config.Interface, answerData map[string]interface{}) (string, string, error) {
+	tmplData, err := appConfig.GetActiveConfigTemplate()
+	if err != nil {
+		return "", "", err
+	}
+
+	tmplConfigFilepath, err := utils.PopulatePathTemplate(filepath.Join(appConfig.GetString("options.config_dir"), tmplData.FilePath), answerData)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmplPemFilepath := ""
+	if tmplData.PemFilePath != "" {
+		tmplPemFilepath, err = utils.PopulatePathTemplate(filepath.Join(appConfig.GetString("options.pem_dir"), tmplData.PemFilePath), answerData)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return tmplConfigFilepath, tmplPemFilepath, nil
+}
+
+func (e *TunnelAction) tunnelDir() (string, error) {
+	return utils.ExpandPath(filepath.Join(e.Config.GetString("options.config_dir"), "tunnels"))
+}
+
+// the config ID names the tunnel state & log files. Answers saved before IDs existed fall back to the rendered filepath.
+func tunnelID(answerData map[string]interface{}, configFilepath string) string {
+	if id := project.ID(answerData); len(id) > 0 {
+		return id
+	}
+	return project.ConfigID(configFilepath)
+}
+
+func (e *TunnelAction) stateFilepath(id string) string {
+	tunnelDir, _ := e.tunnelDir()
+	return filepath.Join(tunnelDir, fmt.Sprintf("%v.yaml", id))
+}
+
+func (e *TunnelAction) logFilepath(id string) string {
+	tunnelDir, _ := e.tunnelDir()
+	return filepath.Join(tunnelDir, fmt.Sprintf("%v.log", id))
+}
+
+func (e *TunnelAction) readState(id string) (TunnelState, error) {
+	return readTunnelStateFile(e.stateFilepath(id))
+}
+
+func (e *TunnelAction) writeState(state TunnelState) error {
+	state.UpdatedAt = time.Now()
+	stateContent, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return utils.FileWrite(e.stateFilepath(state.ID), string(stateContent), 0600, false)
+}
+
+func readTunnelStateFile(stateFilepath string) (TunnelState, error) {
+	stateContent, err := ioutil.ReadFile(stateFilepath)
+	if err != nil {
+		return TunnelState{}, err
+	}
+	state := TunnelState{}
+	err = yaml.Unmarshal(stateContent, &state)
+	if err == nil && strings.TrimSpace(state.ConfigFilepath) == "" {
+		err = errors.ConfigValidationError("tunnel state file is missing config_filepath")
+	}
+	return state, err
+}
@@ -0,0 +1,36 @@
+// +build windows
+
+package actions
+
+import (
+	"os"
+	"syscall"
+)
+
+const createNewProcessGroup = 0x00000200
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: createNewProcessGroup}
+}
+
+// on windows FindProcess opens a handle to the process, and fails if it no longer exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// windows cannot deliver SIGTERM, so the tunnel process is killed outright (and its state file is left to Status to clean up).
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,62 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTunnelAction_Status_CleansUpDeadTunnels(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+
+	stateFilepath := filepath.Join(parentPath, "tunnels", "1a2b3c4d.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(stateFilepath), 0700))
+	require.NoError(t, ioutil.WriteFile(stateFilepath, []byte("pid: 999999999\nid: 1a2b3c4d\nconfig_filepath: /tmp/prod-app-idle-us-east-1\nstatus: running\n"), 0600))
+
+	tunnelAction := actions.TunnelAction{Config: configData}
+
+	//test
+	states, err := tunnelAction.Status()
+
+	//assert
+	require.NoError(t, err, "should not raise an error when reading tunnel state")
+	require.Equal(t, 1, len(states))
+	require.Equal(t, "1a2b3c4d", states[0].ID)
+	require.Equal(t, "dead", states[0].Status, "should detect that the tunnel process is no longer running")
+	require.NoFileExists(t, stateFilepath, "should remove stale state files")
+}
+
+func TestTunnelAction_Run_NoForwards(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+	configData.Set("options.config_dir", parentPath)
+
+	configFilepath := filepath.Join(parentPath, "test-app-live-us-east-1")
+	require.NoError(t, ioutil.WriteFile(configFilepath, []byte("Host bastion\n  Hostname bastion.example.com\n"), 0600))
+
+	tunnelAction := actions.TunnelAction{Config: configData}
+
+	//test
+	err = tunnelAction.Run(configFilepath, "", "")
+
+	//assert
+	require.Error(t, err, "should raise an error when the config does not define any port forwards")
+}
@@ -34,7 +34,7 @@ type GithubReleaseInfo struct {
 // https://github.com/hyperhq/hypercli/blob/302a6b530148f6a777cd6b8772f706ab5e3da46b/hyper/hyper.go
 // https://github.com/inconshreveable/go-update
 //
-func (e *UpdateAction) Start() error {
+func (e *UpdateAction) Start(force bool) error {
 
 	releaseInfo, err := e.GetLatestReleaseInfo()
 	if err != nil {
@@ -78,11 +78,14 @@ func (e *UpdateAction) Start() error {
 		return errors.UpdateBinaryOsArchMissingError(fmt.Sprintf("Cannot find a drawbridge binary for OS/Arch: %v", requiredOsArch))
 	}
 
-	val := utils.StdinQueryBoolean(fmt.Sprintf("Are you sure you would like to update drawbridge to %v?\nPlease confirm [yes/no]:", releaseInfo.TagName))
-
-	if !val {
-		color.Red("Cancelled update operation.")
-		return nil
+	if !force {
+		val, err := utils.StdinQueryBoolean(fmt.Sprintf("Are you sure you would like to update drawbridge to %v?\nPlease confirm [yes/no]:", releaseInfo.TagName))
+		if err != nil {
+			return err
+		} else if !val {
+			color.Red("Cancelled update operation.")
+			return nil
+		}
 	}
 
 	color.Yellow("Updating Drawbridge binary. Please wait...")
@@ -0,0 +1,85 @@
+// +build linux darwin
+
+package actions
+
+import (
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/analogj/drawbridge/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"os/exec"
+	"path/filepath"
+)
+
+type UploadAction struct {
+	ConnectAction
+	Config config.Interface
+}
+
+func (e *UploadAction) Start(answerData map[string]interface{}, localFilePath string, destHostname string, remoteFilePath string, recursive bool) (err error) {
+	log.Debugf("Answer Data: %v", answerData)
+
+	auditEntry := audit.Entry{Action: "upload", Host: destHostname, RemotePath: remoteFilePath, LocalPath: localFilePath}
+	defer func() { e.audit(auditEntry, answerData, err) }()
+
+	tmplData, err := e.Config.GetActiveConfigTemplate()
+	if err != nil {
+		return err
+	}
+
+	tmplConfigFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.config_dir"), tmplData.FilePath), answerData)
+	if err != nil {
+		return err
+	}
+
+	args, err := uploadArgs(tmplConfigFilepath, localFilePath, destHostname, remoteFilePath, recursive)
+	if err != nil {
+		return err
+	}
+
+	if e.Config.GetBool("options.bastion_preflight") && !e.ShellExport {
+		if err := e.BastionPreflight(tmplConfigFilepath); err != nil {
+			return err
+		}
+	}
+
+	pemFilepath := ""
+	if tmplData.PemFilePath != "" {
+		tmplPemFilepath, err := utils.PopulatePathTemplate(filepath.Join(e.Config.GetString("options.pem_dir"), tmplData.PemFilePath), answerData)
+		if err != nil {
+			return err
+		}
+		pemFilepath = tmplPemFilepath
+
+		err = e.loadPemKey(tmplPemFilepath, answerData)
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.ShellExport {
+		return e.exportCommand(args, pemFilepath)
+	}
+
+	if e.Config.GetString("options.transport") == "native" {
+		nativeTransport, err := transport.New(tmplConfigFilepath)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Begin uploading file through bastion (native transport)")
+		e.printCommand(args, true)
+		return nativeTransport.Upload(fmt.Sprintf("%v.in", destHostname), localFilePath, remoteFilePath, utils.SliceIncludes(args, "-r"))
+	}
+
+	fmt.Println("Begin uploading file through bastion")
+	scpBin, lookErr := exec.LookPath("scp")
+	if lookErr != nil {
+		return errors.DependencyMissingError("scp is missing")
+	}
+
+	e.printCommand(args, false)
+	return execProcess(scpBin, args, func() { e.auditStarted(auditEntry, answerData) })
+}
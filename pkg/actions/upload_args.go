@@ -0,0 +1,23 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"os"
+)
+
+// builds the scp argument list used to push a local file (or directory) to an internal host.
+// directories are always copied recursively, scp would refuse them otherwise.
+func uploadArgs(configFilepath string, localFilePath string, destHostname string, remoteFilePath string, recursive bool) ([]string, error) {
+	localInfo, err := os.Stat(localFilePath)
+	if err != nil {
+		return nil, errors.InvalidArgumentsError(fmt.Sprintf("Cannot read local path %v: %v", localFilePath, err))
+	}
+
+	args := []string{"scp", "-F", configFilepath}
+	if recursive || localInfo.IsDir() {
+		args = append(args, "-r")
+	}
+	args = append(args, localFilePath, fmt.Sprintf("%v.in:%v", destHostname, remoteFilePath))
+	return args, nil
+}
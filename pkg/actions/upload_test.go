@@ -0,0 +1,108 @@
+package actions_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/actions"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadAction_Start_MissingLocalPath(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	uploadAction := actions.UploadAction{
+		Config: configData,
+	}
+
+	//test
+	err = uploadAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, filepath.Join(parentPath, "does_not_exist.txt"), "database-1", "/tmp/", false)
+
+	//assert
+	require.Error(t, err, "should raise an error when the local file does not exist")
+}
+
+func TestUploadAction_Start_BastionPreflight(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("options.audit_log", "")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "test-app-live-us-east-1"), []byte("Host bastion\n  Hostname bastion.invalid\n"), 0600))
+	localFilePath := filepath.Join(parentPath, "hotfix.sh")
+	require.NoError(t, ioutil.WriteFile(localFilePath, []byte("#!/bin/sh\n"), 0755))
+	uploadAction := actions.UploadAction{
+		Config: configData,
+	}
+
+	//test
+	err = uploadAction.Start(map[string]interface{}{
+		"environment": "test",
+		"stack_name":  "app",
+		"shard":       "us-east-1",
+		"shard_type":  "live",
+		"username":    "aws",
+	}, localFilePath, "database-1", "/tmp/", false)
+
+	//assert
+	require.IsType(t, errors.BastionUnreachableError(""), err, "should fail the bastion pre-flight before handing off to scp")
+}
+
+func TestUploadAction_Start_ShellExport(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configData.Set("options.config_dir", parentPath)
+	configData.Set("options.pem_dir", parentPath)
+	configData.Set("options.audit_log", "")
+	configData.Set("config_templates", map[string]interface{}{
+		"default": map[string]interface{}{
+			"filepath": "{{.environment}}-{{.shard}}",
+			"content":  "Host bastion\n",
+		},
+	})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(parentPath, "test-us-east-1"), []byte("Host bastion\n  Hostname bastion.invalid\n"), 0600))
+	localFilePath := filepath.Join(parentPath, "hotfix.sh")
+	require.NoError(t, ioutil.WriteFile(localFilePath, []byte("#!/bin/sh\n"), 0755))
+	uploadAction := actions.UploadAction{
+		ConnectAction: actions.ConnectAction{ShellExport: true},
+		Config:        configData,
+	}
+
+	//test
+	err = uploadAction.Start(map[string]interface{}{"environment": "test", "shard": "us-east-1"}, localFilePath, "database-1", "/tmp/", false)
+
+	//assert
+	require.NoError(t, err, "should print the scp command without checking the bastion or running scp")
+}
@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// outcomes recorded in the audit log
+const (
+	// the drawbridge process was replaced by ssh/scp, so the outcome of the session is unknown
+	OutcomeStarted = "started"
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Entry is a single line of the (JSON-lines) audit log
+type Entry struct {
+	Timestamp      time.Time `json:"timestamp" yaml:"timestamp"`
+	User           string    `json:"user" yaml:"user"`
+	Action         string    `json:"action" yaml:"action"`
+	ConfigFilepath string    `json:"config_filepath" yaml:"config_filepath"`
+	ID             string    `json:"id,omitempty" yaml:"id,omitempty"`
+	Aliases        []string  `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// the single alias recorded by older versions of drawbridge, only read from existing audit logs
+	Alias   string                 `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Answers map[string]interface{} `json:"answers,omitempty" yaml:"answers,omitempty"`
+	// the destination host, `bastion` when connecting to the bastion itself
+	Host       string `json:"host" yaml:"host"`
+	RemotePath string `json:"remote_path,omitempty" yaml:"remote_path,omitempty"`
+	LocalPath  string `json:"local_path,omitempty" yaml:"local_path,omitempty"`
+	// the session recording, when connecting with `--record`
+	Recording string `json:"recording,omitempty" yaml:"recording,omitempty"`
+	Outcome   string `json:"outcome" yaml:"outcome"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Filter selects audit log entries. Empty fields match everything.
+type Filter struct {
+	// matches any alias of the config, or its ID
+	Alias string
+	Host  string
+	Since time.Time
+	Until time.Time
+}
+
+// FilePath returns the absolute path of the audit log, or an empty string if `options.audit_log` is disabled.
+// Relative paths are relative to the config_dir.
+func FilePath(appConfig config.Interface) (string, error) {
+	auditLogPath := appConfig.GetString("options.audit_log")
+	if len(auditLogPath) == 0 {
+		return "", nil
+	}
+	if !filepath.IsAbs(auditLogPath) && !strings.HasPrefix(auditLogPath, "~") {
+		auditLogPath = filepath.Join(appConfig.GetString("options.config_dir"), auditLogPath)
+	}
+	return utils.ExpandPath(auditLogPath)
+}
+
+// Append writes the entry to the end of the audit log, filling in the timestamp & current user.
+func Append(auditLogPath string, entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if len(entry.User) == 0 {
+		entry.User = currentUser()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(auditLogPath), 0700); err != nil {
+		return err
+	}
+	auditLog, err := os.OpenFile(auditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	_, err = auditLog.Write(append(line, '\n'))
+	return err
+}
+
+// Read returns the audit log entries matching the filter, oldest first. A missing audit log has no entries.
+func Read(auditLogPath string, filter Filter) ([]Entry, error) {
+	entries := []Entry{}
+	auditLog, err := os.Open(auditLogPath)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer auditLog.Close()
+
+	scanner := bufio.NewScanner(auditLog)
+	// answers can make for long lines
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.ConfigValidationError(fmt.Sprintf("could not parse line %v of the audit log %v: %v", lineNumber, auditLogPath, err))
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Matches returns true if the entry satisfies every field of the filter.
+func (f Filter) Matches(entry Entry) bool {
+	if len(f.Alias) > 0 && f.Alias != entry.ID && f.Alias != entry.Alias && !utils.SliceIncludes(entry.Aliases, f.Alias) {
+		return false
+	}
+	if len(f.Host) > 0 && !strings.EqualFold(f.Host, entry.Host) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseTime parses the `--since`/`--until` values of `drawbridge history`: a date (2006-01-02), an RFC3339 timestamp
+// or a duration (eg. 24h) before now. When endOfDay is true, dates include the whole day.
+func ParseTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp, nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		if endOfDay {
+			return date.AddDate(0, 0, 1), nil
+		}
+		return date, nil
+	}
+	return time.Time{}, errors.InvalidArgumentsError(fmt.Sprintf("`%v` is not a date (2006-01-02), RFC3339 timestamp or duration (24h)", value))
+}
+
+func currentUser() string {
+	if currentUser, err := user.Current(); err == nil {
+		return currentUser.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,104 @@
+package audit_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/audit"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilePath(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", "/tmp/drawbridge")
+
+	//test
+	relativePath, err := audit.FilePath(configData)
+	require.NoError(t, err)
+	configData.Set("options.audit_log", "/var/log/drawbridge/audit.log")
+	absolutePath, err := audit.FilePath(configData)
+	require.NoError(t, err)
+	configData.Set("options.audit_log", "")
+	disabledPath, err := audit.FilePath(configData)
+	require.NoError(t, err)
+
+	//assert
+	require.Equal(t, "/tmp/drawbridge/audit.log", relativePath, "should be relative to the config_dir")
+	require.Equal(t, "/var/log/drawbridge/audit.log", absolutePath)
+	require.Empty(t, disabledPath, "should disable the audit log when empty")
+}
+
+func TestAppend_Read(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	auditLogPath := filepath.Join(parentPath, "logs", "audit.log")
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	//test
+	require.NoError(t, audit.Append(auditLogPath, audit.Entry{Timestamp: yesterday, Action: "connect", Alias: "prod", Host: "bastion", Outcome: audit.OutcomeStarted}))
+	require.NoError(t, audit.Append(auditLogPath, audit.Entry{Action: "download", ID: "1a2b3c4d", Aliases: []string{"live", "prod"}, Host: "database-1", RemotePath: "/tmp/dump.sql", Outcome: audit.OutcomeSuccess}))
+	require.NoError(t, audit.Append(auditLogPath, audit.Entry{Action: "upload", ID: "5e6f7a8b", Aliases: []string{"stage"}, Host: "Database-1", Outcome: audit.OutcomeFailure}))
+	all, err := audit.Read(auditLogPath, audit.Filter{})
+	require.NoError(t, err)
+	byAlias, err := audit.Read(auditLogPath, audit.Filter{Alias: "prod"})
+	require.NoError(t, err)
+	byID, err := audit.Read(auditLogPath, audit.Filter{Alias: "5e6f7a8b"})
+	require.NoError(t, err)
+	byHost, err := audit.Read(auditLogPath, audit.Filter{Host: "database-1"})
+	require.NoError(t, err)
+	recent, err := audit.Read(auditLogPath, audit.Filter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	missing, err := audit.Read(filepath.Join(parentPath, "missing.log"), audit.Filter{})
+
+	//assert
+	require.Len(t, all, 3)
+	require.NotEmpty(t, all[1].User, "should record the current user")
+	require.False(t, all[1].Timestamp.IsZero(), "should record the timestamp")
+	require.Len(t, byAlias, 2, "should match any alias of the config, and the alias of older entries")
+	require.Len(t, byID, 1, "should match the config ID")
+	require.Equal(t, "upload", byID[0].Action)
+	require.Len(t, byHost, 2, "should match hosts case-insensitively")
+	require.Len(t, recent, 2)
+	require.Equal(t, "download", recent[0].Action)
+	require.NoError(t, err)
+	require.Empty(t, missing, "should return no entries when the audit log does not exist")
+	info, err := os.Stat(auditLogPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestParseTime(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	now := time.Date(2020, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	//test
+	duration, durationErr := audit.ParseTime("2h", now, false)
+	date, dateErr := audit.ParseTime("2020-04-01", now, false)
+	endOfDay, endOfDayErr := audit.ParseTime("2020-04-01", now, true)
+	timestamp, timestampErr := audit.ParseTime("2020-04-01T08:30:00Z", now, true)
+	_, invalidErr := audit.ParseTime("yesterday", now, false)
+
+	//assert
+	require.NoError(t, durationErr)
+	require.Equal(t, time.Date(2020, 4, 15, 10, 0, 0, 0, time.UTC), duration)
+	require.NoError(t, dateErr)
+	require.Equal(t, time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), date)
+	require.NoError(t, endOfDayErr)
+	require.Equal(t, time.Date(2020, 4, 2, 0, 0, 0, 0, time.UTC), endOfDay, "should include the whole day")
+	require.NoError(t, timestampErr)
+	require.True(t, time.Date(2020, 4, 1, 8, 30, 0, 0, time.UTC).Equal(timestamp))
+	require.Error(t, invalidErr)
+}
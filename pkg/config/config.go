@@ -39,6 +39,13 @@ func (c *configuration) Init() error {
 	c.SetDefault("options.active_custom_templates", []string{})
 	c.SetDefault("options.ui_group_priority", []string{"environment", "stack_name", "shard", "shard_type"})
 	c.SetDefault("options.ui_question_hidden", []string{})
+	c.SetDefault("options.transport", "exec")
+	c.SetDefault("options.ssh_config_index", "config")
+	c.SetDefault("options.secret_store", "auto")
+	c.SetDefault("options.agent_key_lifetime", 3600)
+	c.SetDefault("options.agent_confirm", false)
+	c.SetDefault("options.bastion_preflight", true)
+	c.SetDefault("options.audit_log", "audit.log")
 
 	c.SetDefault("questions", map[string]Question{
 		"environment": {
@@ -276,6 +283,30 @@ func (c *configuration) ValidateConfigFile(configFilePath string) error {
 						"type":"array",
 						"uniqueItems": true,
 						"items":[{"type":"string"}]
+					},
+					"transport": {
+						"type":"string",
+						"enum": ["exec", "native"]
+					},
+					"ssh_config_index": {
+						"type":"string"
+					},
+					"secret_store": {
+						"type":"string",
+						"enum": ["auto", "keyring", "file", "none"]
+					},
+					"agent_key_lifetime": {
+						"type":"integer",
+						"minimum": 0
+					},
+					"agent_confirm": {
+						"type":"boolean"
+					},
+					"bastion_preflight": {
+						"type":"boolean"
+					},
+					"audit_log": {
+						"type":"string"
 					}
 				}
 			},
@@ -371,6 +402,13 @@ func (c *configuration) ValidateConfigFile(configFilePath string) error {
 							},
 							"pem_filepath": {
 								"type": "string"
+							},
+							"hops": {
+								"type": "array",
+								"items": {
+									"type": "string",
+									"minLength": 1
+								}
 							}
 						}
 					}
@@ -432,7 +470,7 @@ func (c *configuration) ValidateConfigFile(configFilePath string) error {
 
 func (c *configuration) InternalQuestionKeys() []string {
 	//list of internal keys, can be filtered out when printing, etc.
-	return []string{"config_dir", "pem_dir", "active_config_template", "active_custom_templates", "ui_group_priority", "ui_question_hidden", "custom", "config", "template"}
+	return []string{"config_dir", "pem_dir", "active_config_template", "active_custom_templates", "ui_group_priority", "ui_question_hidden", "transport", "ssh_config_index", "secret_store", "agent_key_lifetime", "agent_confirm", "bastion_preflight", "audit_log", "id", "alias", "aliases", "tags", "notes", "custom", "config", "template"}
 }
 
 func (c *configuration) GetProvidedAnswerList() ([]map[string]interface{}, error) {
@@ -530,8 +568,8 @@ func (c *configuration) SetOptionsFromAnswers(answerValues map[string]interface{
 	//find optionKeys in answerValues
 	for _, optionKey := range optionKeys {
 		//check if the key is set as an answer/default
-		if answerOptionValue, ok := answerValues[optionKey]; ok {
-			//this answer is actualy for an option. lets set it.
+		if answerOptionValue, ok := answerValues[optionKey]; ok && optionKey != "audit_log" {
+			//this answer is actualy for an option. lets set it. (the audit log is global, it cannot be set per config)
 			//logger.Debugf("\nSetting option from Answer: %v  (%v)", optionKey, answerOptionValue)
 			options[optionKey] = answerOptionValue
 		}
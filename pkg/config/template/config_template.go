@@ -5,13 +5,17 @@ import (
 	"github.com/analogj/drawbridge/pkg/utils"
 	"github.com/fatih/color"
 	"path/filepath"
+	"strings"
 )
 
 // for configs `filepath`, must be relative to config_dir
 //for configs `pem_filepath` must be relative to pem_dir
+// for configs `hops` is the ordered list of jump hosts (host aliases defined in content, or [user@]host[:port]) that
+// must be traversed before reaching the bastion.
 type ConfigTemplate struct {
 	FileTemplate `mapstructure:",squash"`
-	PemFilePath  string `mapstructure:"pem_filepath"`
+	PemFilePath  string   `mapstructure:"pem_filepath"`
+	Hops         []string `mapstructure:"hops"`
 }
 
 func (t *ConfigTemplate) DeleteTemplate(answerData map[string]interface{}) error {
@@ -19,7 +23,9 @@ func (t *ConfigTemplate) DeleteTemplate(answerData map[string]interface{}) error
 	return t.FileTemplate.DeleteTemplate(answerData)
 }
 
-func (t *ConfigTemplate) WriteTemplate(answerData map[string]interface{}, ignoreKeys []string, dryRun bool) (map[string]interface{}, error) {
+// RenderTemplate populates the config template (with the answers prefix) without writing anything to disk.
+// Returns the templated filepath, the templated content and the template data (persisted in the answers file)
+func (t *ConfigTemplate) RenderTemplate(answerData map[string]interface{}, ignoreKeys []string) (string, string, map[string]interface{}, error) {
 	//intialize template data.
 	if t.data == nil {
 		t.data = map[string]interface{}{}
@@ -27,40 +33,66 @@ func (t *ConfigTemplate) WriteTemplate(answerData map[string]interface{}, ignore
 
 	answerData, err := utils.MapDeepCopy(answerData)
 	if err != nil {
-		return nil, err
+		return "", "", nil, err
 	}
 
 	if t.PemFilePath != "" {
 		// modify/tweak the config template because its a known type.
 		//expand PemFilePath
-		t.PemFilePath = filepath.Join(answerData["pem_dir"].(string), t.PemFilePath)
-		templatedPemFilePath, err := utils.PopulatePathTemplate(t.PemFilePath, answerData)
+		templatedPemFilePath, err := utils.PopulatePathTemplate(filepath.Join(answerData["pem_dir"].(string), t.PemFilePath), answerData)
 		if err != nil {
-			return nil, err
+			return "", "", nil, err
 		}
 
 		t.data["pem_filepath"] = templatedPemFilePath
 		answerData["template"] = t.data
+	}
 
-		if !utils.FileExists(templatedPemFilePath) {
-			color.Yellow("WARNING: PEM file missing. Place it at the following location before attempting to connect. %v", templatedPemFilePath)
+	// populate each hop, so the content can reference the chain as `.template.hops`
+	hops := []string{}
+	for _, hop := range t.Hops {
+		templatedHop, err := utils.PopulateTemplate(hop, answerData)
+		if err != nil {
+			return "", "", nil, err
 		}
+		hops = append(hops, templatedHop)
+	}
+	if len(hops) > 0 {
+		t.data["hops"] = hops
+		answerData["template"] = t.data
 	} else {
-		//pem file path is ""
-		color.Yellow("WARNING: No PEM filepath provided for this config.")
-
+		delete(t.data, "hops")
 	}
 
-	t.FilePath = filepath.Join(answerData["config_dir"].(string), t.FilePath)
-	t.Content = configTemplatePrefix(answerData, ignoreKeys) + t.Content
+	// the config_dir & answers prefix are only applied to a copy, so the template can be rendered multiple times.
+	fileTemplate := t.FileTemplate
+	fileTemplate.FilePath = filepath.Join(answerData["config_dir"].(string), t.FilePath)
+	fileTemplate.Content = configTemplatePrefix(answerData, ignoreKeys) + t.Content + configTemplateSuffix(hops)
 
-	_, err = t.FileTemplate.WriteTemplate(answerData, dryRun)
+	return fileTemplate.RenderTemplate(answerData)
+}
+
+func (t *ConfigTemplate) WriteTemplate(answerData map[string]interface{}, ignoreKeys []string, dryRun bool) (map[string]interface{}, error) {
+	templatedFilePath, templatedContent, templateData, err := t.RenderTemplate(answerData, ignoreKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	return t.data, nil
+	if pemFilePath, ok := templateData["pem_filepath"].(string); ok {
+		if !utils.FileExists(pemFilePath) {
+			color.Yellow("WARNING: PEM file missing. Place it at the following location (or use `drawbridge key import`/`drawbridge key generate`) before attempting to connect. %v", pemFilePath)
+		}
+	} else {
+		//pem file path is ""
+		color.Yellow("WARNING: No PEM filepath provided for this config.")
+	}
+
+	err = writeRenderedTemplate(templatedFilePath, templatedContent, dryRun)
+	if err != nil {
+		return nil, err
+	}
 
+	return templateData, nil
 }
 
 func configTemplatePrefix(answerData map[string]interface{}, ignoreKeys []string) string {
@@ -83,3 +115,12 @@ func configTemplatePrefix(answerData map[string]interface{}, ignoreKeys []string
 	prefix += "\n"
 	return prefix
 }
+
+// routes the bastion through the hop chain. ssh uses the first value it finds for each option, so this block is
+// appended after the template content, allowing the template to override ProxyJump explicitly.
+func configTemplateSuffix(hops []string) string {
+	if len(hops) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n# Bastion hop chain\nHost bastion\n  ProxyJump %v\n", strings.Join(hops, ","))
+}
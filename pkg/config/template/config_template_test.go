@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -176,3 +177,35 @@ func TestConfigTemplate_WriteTemplate_ShouldGenerateValidPrefix(t *testing.T) {
 //	//assert
 //	require.Error(t, err,"should raise an error if destination file already exists.")
 //}
+
+func TestConfigTemplate_RenderTemplate_Hops(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	defer os.RemoveAll(parentPath)
+
+	configTemplate := template.ConfigTemplate{
+		PemFilePath: "{{.example}}.pem",
+		Hops:        []string{"edge", "jump.{{.example}}.example.com"},
+		FileTemplate: template.FileTemplate{
+			FilePath: "{{.example}}.text",
+			Template: template.Template{
+				Content: "Host bastion\n  Hostname bastion.example.com\n# via {{stringsJoin .template.hops \" \"}}",
+			},
+		},
+	}
+
+	//test
+	_, actualContent, actualData, err := configTemplate.RenderTemplate(map[string]interface{}{
+		"example":    "1",
+		"config_dir": parentPath,
+		"pem_dir":    parentPath,
+	}, []string{"example", "config_dir", "pem_dir", "template"})
+
+	//assert
+	require.NoError(t, err, "should not raise an error when rendering hops")
+	require.Equal(t, []string{"edge", "jump.1.example.com"}, actualData["hops"], "hops should be templated & persisted in the template data")
+	require.Contains(t, actualContent, "# via edge jump.1.example.com", "hops should be available to the content template")
+	require.True(t, strings.HasSuffix(actualContent, "Host bastion\n  ProxyJump edge,jump.1.example.com\n"), "bastion should be routed through the hop chain")
+}
@@ -42,46 +42,60 @@ func (t *FileTemplate) DeleteTemplate(answerData map[string]interface{}) error {
 	}
 }
 
-func (t *FileTemplate) WriteTemplate(answerData map[string]interface{}, dryRun bool) (map[string]interface{}, error) {
+// RenderTemplate populates the filepath & content templates without writing anything to disk.
+// Returns the templated filepath, the templated content and the template data (persisted in the answers file)
+func (t *FileTemplate) RenderTemplate(answerData map[string]interface{}) (string, string, map[string]interface{}, error) {
 	if t.data == nil {
 		t.data = map[string]interface{}{}
 	}
 
 	answerData, err := utils.MapDeepCopy(answerData)
 	if err != nil {
-		return nil, err
+		return "", "", nil, err
 	}
 
 	templatedFilePath, err := utils.PopulatePathTemplate(t.FilePath, answerData)
 	if err != nil {
-		return nil, err
+		return "", "", nil, err
 	}
 
 	t.data["filepath"] = templatedFilePath
 	answerData["template"] = t.data
 
 	templatedContent, err := utils.PopulateTemplate(t.Content, answerData)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return templatedFilePath, templatedContent, t.data, nil
+}
+
+func (t *FileTemplate) WriteTemplate(answerData map[string]interface{}, dryRun bool) (map[string]interface{}, error) {
+	templatedFilePath, templatedContent, templateData, err := t.RenderTemplate(answerData)
 	if err != nil {
 		return nil, err
 	}
 
-	if !utils.FileExists(templatedFilePath) {
+	err = writeRenderedTemplate(templatedFilePath, templatedContent, dryRun)
+	if err != nil {
+		return nil, err
+	}
 
-		//make the file's parent directory.
-		err = os.MkdirAll(filepath.Dir(templatedFilePath), 0777)
-		if err != nil {
-			return nil, err
-		}
+	return templateData, nil
+}
 
-		log.Printf("Writing template to %v", templatedFilePath)
-		err = utils.FileWrite(templatedFilePath, templatedContent, 0644, dryRun)
-		if err != nil {
-			return nil, err
-		}
+// writes rendered template content to a new file, refusing to overwrite existing files.
+func writeRenderedTemplate(templatedFilePath string, templatedContent string, dryRun bool) error {
+	if utils.FileExists(templatedFilePath) {
+		return errors.TemplateFileExistsError(fmt.Sprintf("file at %v already exists. Cannot write template file", templatedFilePath))
+	}
 
-	} else {
-		return nil, errors.TemplateFileExistsError(fmt.Sprintf("file at %v already exists. Cannot write template file", templatedFilePath))
+	//make the file's parent directory.
+	err := os.MkdirAll(filepath.Dir(templatedFilePath), 0777)
+	if err != nil {
+		return err
 	}
 
-	return t.data, nil
+	log.Printf("Writing template to %v", templatedFilePath)
+	return utils.FileWrite(templatedFilePath, templatedContent, 0644, dryRun)
 }
@@ -12,6 +12,11 @@ type PacTemplate struct {
 	FileTemplate `mapstructure:",squash"`
 }
 
+// Render populates the PAC template with the answers, without writing it to disk.
+func (t *PacTemplate) Render(answerDataList []map[string]interface{}) (string, error) {
+	return utils.PopulateTemplate(t.Content, answerDataList)
+}
+
 func (t *PacTemplate) WriteTemplate(answerDataList []map[string]interface{}, dryRun bool) (map[string]interface{}, error) {
 	if t.data == nil {
 		t.data = map[string]interface{}{}
@@ -24,7 +29,7 @@ func (t *PacTemplate) WriteTemplate(answerDataList []map[string]interface{}, dry
 
 	t.data["filepath"] = pacFilePath
 
-	templatedContent, err := utils.PopulateTemplate(t.Content, answerDataList)
+	templatedContent, err := t.Render(answerDataList)
 	if err != nil {
 		return nil, err
 	}
@@ -90,3 +90,52 @@ type InvalidArgumentsError string
 func (str InvalidArgumentsError) Error() string {
 	return fmt.Sprintf("InvalidArgumentsError: %q", string(str))
 }
+
+// Raised when the native ssh transport encounters a protocol error
+type TransportError string
+
+func (str TransportError) Error() string {
+	return fmt.Sprintf("TransportError: %q", string(str))
+}
+
+// Raised when the local proxy server cannot handle a client request
+type ProxyError string
+
+func (str ProxyError) Error() string {
+	return fmt.Sprintf("ProxyError: %q", string(str))
+}
+
+// Raised when `drawbridge check` finds stale, invalid or orphaned configs
+type CheckFailedError string
+
+func (str CheckFailedError) Error() string {
+	return fmt.Sprintf("CheckFailedError: %q", string(str))
+}
+
+// Raised when drawbridge needs to prompt the user, but is running in non-interactive mode
+type InteractivePromptError string
+
+func (str InteractivePromptError) Error() string {
+	return fmt.Sprintf("InteractivePromptError: %q", string(str))
+}
+
+// Raised when a command run with `drawbridge exec` fails on one or more internal hosts
+type RemoteCommandError string
+
+func (str RemoteCommandError) Error() string {
+	return fmt.Sprintf("RemoteCommandError: %q", string(str))
+}
+
+// Raised when PEM keys or passphrases cannot be read from (or written to) the secret store
+type SecretStoreError string
+
+func (str SecretStoreError) Error() string {
+	return fmt.Sprintf("SecretStoreError: %q", string(str))
+}
+
+// Raised when the bastion host (or the first jump host) of a config cannot be reached
+type BastionUnreachableError string
+
+func (str BastionUnreachableError) Error() string {
+	return fmt.Sprintf("BastionUnreachableError: %q", string(str))
+}
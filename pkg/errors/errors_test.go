@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	"fmt"
 	"github.com/analogj/drawbridge/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -36,4 +37,21 @@ func TestErrors(t *testing.T) {
 	require.Implements(t, (*error)(nil), errors.AnswerFormatError("test"), "should implement the error interface")
 	require.Implements(t, (*error)(nil), errors.DependencyMissingError("test"), "should implement the error interface")
 	require.Implements(t, (*error)(nil), errors.PemKeyMissingError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.TransportError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.ProxyError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.CheckFailedError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.InteractivePromptError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.RemoteCommandError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.SecretStoreError("test"), "should implement the error interface")
+	require.Implements(t, (*error)(nil), errors.BastionUnreachableError("test"), "should implement the error interface")
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	//assert
+	require.Equal(t, errors.ExitCodeSuccess, errors.ExitCode(nil))
+	require.Equal(t, errors.ExitCodeUnknown, errors.ExitCode(fmt.Errorf("test")), "should return the generic exit code for unknown errors")
+	require.Equal(t, errors.ExitCodeConfigValidation, errors.ExitCode(errors.ConfigValidationError("test")))
+	require.Equal(t, errors.ExitCodeInteractivePrompt, errors.ExitCode(errors.InteractivePromptError("test")))
 }
@@ -0,0 +1,78 @@
+package errors
+
+// Exit codes returned by the drawbridge binary, so that scripts can distinguish between failures.
+// These are part of the public interface, documented in the README. Do not renumber existing codes.
+const (
+	ExitCodeSuccess                   = 0
+	ExitCodeUnknown                   = 1
+	ExitCodeInvalidArguments          = 2
+	ExitCodeConfigFileMissing         = 3
+	ExitCodeConfigValidation          = 4
+	ExitCodeDependencyMissing         = 5
+	ExitCodePemKeyMissing             = 6
+	ExitCodeTemplateFileExists        = 7
+	ExitCodeQuestionKeyInvalid        = 8
+	ExitCodeAnswerValidation          = 9
+	ExitCodeAnswerFormat              = 10
+	ExitCodeUpdateNotAvailable        = 11
+	ExitCodeUpdateBinaryOsArchMissing = 12
+	ExitCodeProjectListEmpty          = 13
+	ExitCodeProjectListIndexInvalid   = 14
+	ExitCodeTransport                 = 15
+	ExitCodeProxy                     = 16
+	ExitCodeCheckFailed               = 17
+	ExitCodeInteractivePrompt         = 18
+	ExitCodeRemoteCommand             = 19
+	ExitCodeSecretStore               = 20
+	ExitCodeBastionUnreachable        = 21
+)
+
+// ExitCode returns the process exit code for an error returned by a drawbridge command
+func ExitCode(err error) int {
+	switch err.(type) {
+	case nil:
+		return ExitCodeSuccess
+	case InvalidArgumentsError:
+		return ExitCodeInvalidArguments
+	case ConfigFileMissingError:
+		return ExitCodeConfigFileMissing
+	case ConfigValidationError:
+		return ExitCodeConfigValidation
+	case DependencyMissingError:
+		return ExitCodeDependencyMissing
+	case PemKeyMissingError:
+		return ExitCodePemKeyMissing
+	case TemplateFileExistsError:
+		return ExitCodeTemplateFileExists
+	case QuestionKeyInvalidError:
+		return ExitCodeQuestionKeyInvalid
+	case AnswerValidationError:
+		return ExitCodeAnswerValidation
+	case AnswerFormatError:
+		return ExitCodeAnswerFormat
+	case UpdateNotAvailableError:
+		return ExitCodeUpdateNotAvailable
+	case UpdateBinaryOsArchMissingError:
+		return ExitCodeUpdateBinaryOsArchMissing
+	case ProjectListEmptyError:
+		return ExitCodeProjectListEmpty
+	case ProjectListIndexInvalidError:
+		return ExitCodeProjectListIndexInvalid
+	case TransportError:
+		return ExitCodeTransport
+	case ProxyError:
+		return ExitCodeProxy
+	case CheckFailedError:
+		return ExitCodeCheckFailed
+	case InteractivePromptError:
+		return ExitCodeInteractivePrompt
+	case RemoteCommandError:
+		return ExitCodeRemoteCommand
+	case SecretStoreError:
+		return ExitCodeSecretStore
+	case BastionUnreachableError:
+		return ExitCodeBastionUnreachable
+	default:
+		return ExitCodeUnknown
+	}
+}
@@ -0,0 +1,160 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+)
+
+// AliasPattern is the format of config aliases
+const AliasPattern = `^[A-Za-z][\w-\.]+$`
+
+// AliasEntry is the machine-readable representation of an alias, used by `drawbridge alias list --output json|yaml`
+type AliasEntry struct {
+	Alias          string `json:"alias" yaml:"alias"`
+	Index          int    `json:"index" yaml:"index"`
+	ID             string `json:"id,omitempty" yaml:"id,omitempty"`
+	ConfigFilePath string `json:"config_filepath" yaml:"config_filepath"`
+}
+
+// Aliases returns the aliases of a config. Answers files written before configs could have multiple aliases store a
+// single `alias` string instead of the `aliases` list.
+func Aliases(answerData map[string]interface{}) []string {
+	aliases := stringList(answerData, "aliases")
+	if alias, ok := answerData["alias"].(string); ok && len(alias) > 0 && !utils.SliceIncludes(aliases, alias) {
+		aliases = append([]string{alias}, aliases...)
+	}
+	return aliases
+}
+
+// PrimaryAlias returns the first alias of a config (used in logs & status output), or an empty string.
+func PrimaryAlias(answerData map[string]interface{}) string {
+	if aliases := Aliases(answerData); len(aliases) > 0 {
+		return aliases[0]
+	}
+	return ""
+}
+
+// ValidateAlias returns an error if the alias does not match the AliasPattern, or could be mistaken for a config ID.
+func ValidateAlias(alias string) error {
+	if isValid, err := regexp.MatchString(AliasPattern, alias); err != nil || !isValid {
+		return errors.InvalidArgumentsError(fmt.Sprintf("invalid alias `%v`, must match pattern: %v", alias, AliasPattern))
+	}
+	if LooksLikeID(alias) {
+		return errors.InvalidArgumentsError(fmt.Sprintf("invalid alias `%v`, must not look like a config ID (8 hex characters)", alias))
+	}
+	return nil
+}
+
+// GetAllAliases returns every alias, sorted by name.
+func (p *ProjectList) GetAllAliases() []AliasEntry {
+	aliasEntries := []AliasEntry{}
+	for _, entry := range p.GetAllEntries() {
+		for _, alias := range entry.Aliases {
+			aliasEntries = append(aliasEntries, AliasEntry{Alias: alias, Index: entry.Index, ID: entry.ID, ConfigFilePath: entry.ConfigFilePath})
+		}
+	}
+	sort.Slice(aliasEntries, func(i, j int) bool {
+		return aliasEntries[i].Alias < aliasEntries[j].Alias
+	})
+	return aliasEntries
+}
+
+// AddAliasForIndex adds an alias to the config. Aliases must be unique across all configs.
+func (p *ProjectList) AddAliasForIndex(index_0based int, alias string) (map[string]interface{}, error) {
+	if err := p.ensureAliasAvailable(alias); err != nil {
+		return nil, err
+	}
+	return p.updateAliases(index_0based, func(aliases []string) []string {
+		return append(aliases, alias)
+	})
+}
+
+// RemoveAlias removes the alias from the config that has it.
+func (p *ProjectList) RemoveAlias(alias string) (map[string]interface{}, error) {
+	_, index, err := p.GetWithAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	return p.updateAliases(index, func(aliases []string) []string {
+		return removeAlias(aliases, alias)
+	})
+}
+
+// RenameAlias replaces the alias with newAlias, keeping its position in the config's aliases.
+func (p *ProjectList) RenameAlias(alias string, newAlias string) (map[string]interface{}, error) {
+	_, index, err := p.GetWithAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ensureAliasAvailable(newAlias); err != nil {
+		return nil, err
+	}
+	return p.updateAliases(index, func(aliases []string) []string {
+		for ndx := range aliases {
+			if aliases[ndx] == alias {
+				aliases[ndx] = newAlias
+			}
+		}
+		return aliases
+	})
+}
+
+func (p *ProjectList) ensureAliasAvailable(alias string) error {
+	if err := ValidateAlias(alias); err != nil {
+		return err
+	}
+	if _, index, err := p.GetWithID(alias); err == nil {
+		return errors.ConfigValidationError(fmt.Sprintf("alias `%v` is the ID of config %v", alias, index+1))
+	}
+	if _, index, err := p.GetWithAlias(alias); err == nil {
+		return errors.ConfigValidationError(fmt.Sprintf("alias `%v` already exists (config %v)", alias, index+1))
+	}
+	return nil
+}
+
+// updates the aliases of the config. Legacy `alias` strings are migrated to the `aliases` list.
+func (p *ProjectList) updateAliases(index_0based int, update func(aliases []string) []string) (map[string]interface{}, error) {
+	return p.updateAnswerFile(index_0based, func(answerData map[string]interface{}) {
+		aliases := update(Aliases(answerData))
+		delete(answerData, "alias")
+		setStringList(answerData, "aliases", aliases)
+	})
+}
+
+// aliases must be unique across all configs. When answers files share an alias (eg. after being copied by hand), the
+// alias is kept for the first config only.
+func (p *ProjectList) dedupeAliases() {
+	seenAliases := map[string]string{}
+	for _, project := range p.projects {
+		aliases := Aliases(project.Answers)
+		if len(aliases) == 0 {
+			continue
+		}
+		uniqueAliases := []string{}
+		for _, alias := range aliases {
+			if otherAnswerFilePath, seen := seenAliases[alias]; seen {
+				color.HiYellow("WARNING: alias (%v) of %v is already used by %v, ignoring it", alias, project.AnswerFilePath, otherAnswerFilePath)
+				continue
+			}
+			seenAliases[alias] = project.AnswerFilePath
+			uniqueAliases = append(uniqueAliases, alias)
+		}
+		delete(project.Answers, "alias")
+		setStringList(project.Answers, "aliases", uniqueAliases)
+	}
+}
+
+func removeAlias(aliases []string, alias string) []string {
+	remaining := []string{}
+	for _, existingAlias := range aliases {
+		if existingAlias != alias {
+			remaining = append(remaining, existingAlias)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,160 @@
+package project_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createTempProjectList(t *testing.T, configDir string) (config.Interface, project.ProjectList) {
+	require.NoError(t, utils.CopyDir(filepath.Join("testdata", "config_dir"), configDir))
+
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", configDir)
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+	return testConfig, projList
+}
+
+func appendToFile(t *testing.T, filePath string, content string) {
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+}
+
+func TestAliases(t *testing.T) {
+	t.Parallel()
+
+	//test
+	legacyAliases := project.Aliases(map[string]interface{}{"alias": "legacy"})
+	aliases := project.Aliases(map[string]interface{}{"aliases": []interface{}{"first", "second"}})
+	mixedAliases := project.Aliases(map[string]interface{}{"alias": "legacy", "aliases": []string{"first"}})
+
+	//assert
+	require.Equal(t, []string{"legacy"}, legacyAliases)
+	require.Equal(t, []string{"first", "second"}, aliases)
+	require.Equal(t, []string{"legacy", "first"}, mixedAliases)
+	require.Equal(t, "first", project.PrimaryAlias(map[string]interface{}{"aliases": []interface{}{"first", "second"}}))
+	require.Empty(t, project.PrimaryAlias(map[string]interface{}{}))
+}
+
+func TestValidateAlias(t *testing.T) {
+	t.Parallel()
+
+	//assert
+	require.NoError(t, project.ValidateAlias("prod-app.us_east"))
+	require.Error(t, project.ValidateAlias("1prod"))
+	require.Error(t, project.ValidateAlias("prod app"))
+	require.Error(t, project.ValidateAlias("deadbeef"), "should reject aliases that look like a config ID")
+	require.Error(t, project.ValidateAlias("DEADBEEF"), "should reject aliases that look like a config ID")
+	require.NoError(t, project.ValidateAlias("deadbeef1"))
+}
+
+func TestProjectList_AddRemoveRenameAlias(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	testConfig, projList := createTempProjectList(t, filepath.Join(parentPath, "config_dir"))
+
+	//test
+	_, err = projList.AddAliasForIndex(0, "first")
+	require.NoError(t, err)
+	_, err = projList.AddAliasForIndex(0, "second")
+	require.NoError(t, err)
+	_, duplicateErr := projList.AddAliasForIndex(1, "first")
+	_, invalidErr := projList.AddAliasForIndex(1, "1invalid")
+	_, err = projList.RenameAlias("second", "renamed")
+	require.NoError(t, err)
+	_, renameDuplicateErr := projList.RenameAlias("renamed", "first")
+	_, err = projList.RemoveAlias("first")
+	require.NoError(t, err)
+	_, removeMissingErr := projList.RemoveAlias("first")
+
+	reloadedList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err)
+	_, index, aliasErr := reloadedList.GetWithAlias("renamed")
+	_, _, removedErr := reloadedList.GetWithAlias("first")
+
+	//assert
+	require.Error(t, duplicateErr, "aliases should be unique across configs")
+	require.Error(t, invalidErr)
+	require.Error(t, renameDuplicateErr)
+	require.Error(t, removeMissingErr)
+	require.NoError(t, aliasErr)
+	require.Equal(t, 0, index)
+	require.Error(t, removedErr)
+	require.Equal(t, []project.AliasEntry{{Alias: "renamed", Index: 1, ID: reloadedList.GetAllEntries()[0].ID, ConfigFilePath: reloadedList.GetAllEntries()[0].ConfigFilePath}}, reloadedList.GetAllAliases())
+}
+
+func TestCreateProjectListFromConfigDir_LegacyAndDuplicateAliases(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configDir := filepath.Join(parentPath, "config_dir")
+	require.NoError(t, utils.CopyDir(filepath.Join("testdata", "config_dir"), configDir))
+	appendToFile(t, filepath.Join(configDir, ".prod-app-idle-us-east-1.answers.yaml"), "alias: legacy\n")
+	appendToFile(t, filepath.Join(configDir, ".test-app-idle-us-east-1.answers.yaml"), "aliases:\n- legacy\n- other\n")
+
+	testConfig, _ := config.Create()
+	err = testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", configDir)
+
+	//test
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err)
+	legacyAnswers, _, legacyErr := projList.GetWithAlias("legacy")
+	otherAnswers, _, otherErr := projList.GetWithAlias("other")
+
+	//assert
+	require.NoError(t, legacyErr)
+	require.Equal(t, "/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1", legacyAnswers["config"].(map[string]interface{})["filepath"], "should migrate the single alias string")
+	require.NotContains(t, legacyAnswers, "alias")
+	require.NoError(t, otherErr)
+	require.Equal(t, []string{"other"}, project.Aliases(otherAnswers), "should drop the alias already used by another config")
+}
+
+func TestProjectList_AddAliasForIndex_ConfigID(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configDir := filepath.Join(parentPath, "config_dir")
+	require.NoError(t, utils.CopyDir(filepath.Join("testdata", "config_dir"), configDir))
+	appendToFile(t, filepath.Join(configDir, ".prod-app-idle-us-east-1.answers.yaml"), "id: custom-id\n")
+
+	testConfig, _ := config.Create()
+	err = testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", configDir)
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err)
+	_, index, err := projList.GetWithID("custom-id")
+	require.NoError(t, err)
+
+	//test
+	_, existingIDErr := projList.AddAliasForIndex(index+1, "custom-id")
+	_, hexErr := projList.AddAliasForIndex(index+1, "deadbeef")
+
+	//assert
+	require.Error(t, existingIDErr, "aliases should not equal the ID of another config")
+	require.Error(t, hexErr, "aliases should not look like a config ID")
+}
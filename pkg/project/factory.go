@@ -2,7 +2,9 @@ package project
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
 	"github.com/analogj/drawbridge/pkg/utils"
 	"gopkg.in/yaml.v2"
 	"log"
@@ -20,7 +22,7 @@ func CreateProjectListFromConfigDir(configData config.Interface) (ProjectList, e
 	projectList.hiddenKeys = append(projectList.hiddenKeys, configData.GetStringSlice("options.ui_question_hidden")...)
 	projectList.hiddenKeys = append(projectList.hiddenKeys, configData.InternalQuestionKeys()...)
 
-	answerFiles, err := answerFilesInConfigDir(configData.GetString("options.config_dir"))
+	answerFiles, err := AnswerFilesInConfigDir(configData.GetString("options.config_dir"))
 	if err != nil {
 		return projectList, err
 	}
@@ -35,6 +37,7 @@ func CreateProjectListFromConfigDir(configData config.Interface) (ProjectList, e
 		projectList.projects = append(projectList.projects, answerProjectData)
 
 	}
+	projectList.dedupeAliases()
 
 	return projectList, nil
 }
@@ -71,7 +74,7 @@ func CreateProjectFromConfigDirAnswerFile(configFilePath string) (projectData, e
 ///////////////////////////////////////////////////////////////////////////////
 // Helpers
 
-func answerFilesInConfigDir(configDir string) ([]string, error) {
+func AnswerFilesInConfigDir(configDir string) ([]string, error) {
 	configDir, err := utils.ExpandPath(configDir)
 	if err != nil {
 		return nil, err
@@ -106,18 +109,35 @@ func parseAnswerFile(answerFilePath string) (projectData, error) {
 		answerData[k] = utils.StringifyYAMLMapKeys(v)
 	}
 
-	//TODO: warn the user if the answer data would no longer render the same answers.yaml file.
+	// answer data that would no longer render the same files is reported by `drawbridge check`
+
+	answerDataConfig, ok := answerData["config"].(map[string]interface{})
+	if !ok {
+		return projectData{}, errors.AnswerFormatError(fmt.Sprintf("%v is missing the rendered config data", answerFilePath))
+	}
+	configFilePath, ok := answerDataConfig["filepath"].(string)
+	if !ok {
+		return projectData{}, errors.AnswerFormatError(fmt.Sprintf("%v is missing the rendered config filepath", answerFilePath))
+	}
+	// answers files created before config IDs were introduced get theirs the next time they are written
+	if _, ok := answerData["id"].(string); !ok {
+		answerData["id"] = ConfigID(configFilePath)
+	}
+	// answers files created before configs could have multiple aliases store a single `alias` string
+	if _, ok := answerData["alias"]; ok {
+		setStringList(answerData, "aliases", Aliases(answerData))
+		delete(answerData, "alias")
+	}
 
-	answerDataConfig := answerData["config"].(map[string]interface{})
 	pemFilePath := "" //this is an optional field (may be unset/nil in some configs)
-	if val, ok := answerDataConfig["pem_filepath"]; ok {
-		pemFilePath = val.(string)
+	if val, ok := answerDataConfig["pem_filepath"].(string); ok {
+		pemFilePath = val
 	}
 
 	return projectData{
 		Answers:        answerData,
 		AnswerFilePath: answerFilePath,
-		ConfigFilePath: answerDataConfig["filepath"].(string),
+		ConfigFilePath: configFilePath,
 		PemFilePath:    pemFilePath,
 	}, nil
 
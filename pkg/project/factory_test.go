@@ -27,6 +27,7 @@ func TestCreateProjectFromConfigDirAnswerFile(t *testing.T) {
 		"config_dir":         "~/.ssh/drawbridge",
 		"custom":             []interface{}{},
 		"environment":        "test",
+		"id":                 "c0ffee00",
 		"pem_dir":            "~/.ssh/drawbridge/pem",
 		"shard":              "us-east-1",
 		"shard_type":         "idle",
@@ -34,7 +35,7 @@ func TestCreateProjectFromConfigDirAnswerFile(t *testing.T) {
 		"ui_group_priority":  []interface{}{"environment", "stack_name", "shard", "shard_type"},
 		"ui_question_hidden": []interface{}{},
 		"username":           "aws",
-	}, proj.Answers, "should parse populate")
+	}, proj.Answers, "should parse populate, keeping the stored config id")
 	require.Equal(t, answerFile, proj.AnswerFilePath, "correctly set the answerfile path")
 	require.Equal(t, "/Users/jason/.ssh/drawbridge/test-app-idle-us-east-1", proj.ConfigFilePath, "correctly set the config filepath")
 	require.Equal(t, "/Users/jason/.ssh/drawbridge/pem/test/aws-test.pem", proj.PemFilePath, "correctly set the pem filepath")
@@ -102,6 +103,7 @@ func TestCreateProjectListFromConfigDir(t *testing.T) {
 			"pem_filepath": "/Users/jason/.ssh/drawbridge/pem/prod/aws-prod.pem",
 		},
 		"environment":       "prod",
+		"id":                project.ConfigID("/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1"),
 		"pem_dir":           "~/.ssh/drawbridge/pem",
 		"shard_type":        "idle",
 		"stack_name":        "app",
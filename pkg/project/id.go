@@ -0,0 +1,49 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+// length of the hex encoded config ID
+const idLength = 8
+
+// IDPattern is the format of config IDs
+const IDPattern = `^(?i)[0-9a-f]{8}$`
+
+// ConfigID returns the short, stable identifier of a config: a hash of the rendered config filepath. It is stored in
+// the answers file (as `id`) when the config is created, so it does not change if the config is later moved.
+func ConfigID(configFilePath string) string {
+	hash := sha256.Sum256([]byte(configFilePath))
+	return hex.EncodeToString(hash[:])[:idLength]
+}
+
+// LooksLikeID returns true if the value has the format of a config ID.
+func LooksLikeID(value string) bool {
+	isID, _ := regexp.MatchString(IDPattern, value)
+	return isID
+}
+
+// ID returns the config ID stored in the answers, or an empty string.
+func ID(answerData map[string]interface{}) string {
+	id, _ := answerData["id"].(string)
+	return id
+}
+
+// GetWithID returns the project with the config ID.
+func (p *ProjectList) GetWithID(id string) (map[string]interface{}, int, error) {
+	if p.Length() == 0 {
+		return nil, 0, errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
+	}
+
+	for ndx, answerData := range p.GetAll() {
+		if answerID, answerIDOk := answerData["id"].(string); answerIDOk && answerID == id {
+			return answerData, ndx, nil
+		}
+	}
+	return nil, 0, errors.ProjectListIndexInvalidError(fmt.Sprintf("ID (%v) not found", id))
+}
@@ -0,0 +1,82 @@
+package project_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigID(t *testing.T) {
+	t.Parallel()
+
+	//test
+	id := project.ConfigID("/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1")
+
+	//assert
+	require.Len(t, id, 8)
+	require.Equal(t, id, project.ConfigID("/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1"), "should be stable")
+	require.NotEqual(t, id, project.ConfigID("/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-2"))
+}
+
+func TestProjectList_GetWithID(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", filepath.Join("testdata", "config_dir"))
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+	id := project.ConfigID("/Users/jason/.ssh/drawbridge/test-app-idle-us-east-2")
+
+	//test
+	answerData, index, err := projList.GetWithAliasOrIndex(id)
+	entry, entryErr := projList.GetEntryWithAliasOrIndex(id)
+	_, _, missingErr := projList.GetWithID("00000000")
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "/Users/jason/.ssh/drawbridge/test-app-idle-us-east-2", answerData["config"].(map[string]interface{})["filepath"])
+	require.NoError(t, entryErr)
+	require.Equal(t, id, entry.ID)
+	require.Equal(t, index+1, entry.Index)
+	require.Error(t, missingErr)
+}
+
+func TestProjectList_AddAliasForIndex(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	configDir := filepath.Join(parentPath, "config_dir")
+	require.NoError(t, utils.CopyDir(filepath.Join("testdata", "config_dir"), configDir))
+
+	testConfig, _ := config.Create()
+	err = testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", configDir)
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+	selected, _, err := projList.GetWithIndex(1)
+	require.NoError(t, err)
+
+	//test
+	_, err = projList.AddAliasForIndex(1, "selected")
+	require.NoError(t, err)
+	reloadedList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err)
+	aliased, _, aliasErr := reloadedList.GetWithAlias("selected")
+
+	//assert
+	require.NoError(t, aliasErr)
+	require.Equal(t, selected["config"], aliased["config"], "should set the alias on the config at the printed index")
+	require.Equal(t, selected["id"], aliased["id"], "should persist the config id")
+}
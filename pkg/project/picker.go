@@ -0,0 +1,292 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// terminal control sequences used by the picker
+const (
+	pickerEnterScreen = "\x1b[?1049h\x1b[?7l"
+	pickerExitScreen  = "\x1b[?7h\x1b[?1049l"
+	pickerClearScreen = "\x1b[H\x1b[2J"
+)
+
+type pickerItem struct {
+	label string
+	// lower-cased alias & answer values, each query term must fuzzy match one of them
+	values []string
+}
+
+// picker is a full-screen, type-to-filter selector over the grouped answers. Items keep the `ui_group_priority` order.
+type picker struct {
+	message string
+	items   []pickerItem
+	height  int
+
+	query   []rune
+	matches []int
+	cursor  int
+}
+
+// Pick reads key presses from in (a terminal in raw mode) and draws the picker on out, returning the selected answers.
+// Typing filters the configs, the arrow keys (or ctrl-p/ctrl-n) move the selection, enter selects and esc/ctrl-c cancels.
+func (p *ProjectList) Pick(message string, in io.Reader, out io.Writer, height int) (map[string]interface{}, int, error) {
+	if p.Length() == 0 {
+		return nil, 0, errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
+	}
+
+	if len(p.groupedAnswersList) == 0 {
+		p.initGroups()
+	}
+
+	selector := picker{message: message, height: height}
+	for ndx, answer := range p.groupedAnswersList {
+		selector.items = append(selector.items, pickerItem{label: p.pickerLabel(ndx, answer), values: pickerValues(answer)})
+	}
+	selector.filter()
+
+	foundIndex, err := selector.run(in, out)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.groupedAnswersList[foundIndex], foundIndex, nil
+}
+
+// uses the picker when attached to a terminal, returns false when the numeric prompt should be used instead.
+func (p *ProjectList) promptWithPicker(message string) (map[string]interface{}, int, bool, error) {
+	stdinFd := int(os.Stdin.Fd())
+	stdoutFd := int(os.Stdout.Fd())
+	if utils.NonInteractive || !terminal.IsTerminal(stdinFd) || !terminal.IsTerminal(stdoutFd) || os.Getenv("TERM") == "dumb" {
+		return nil, 0, false, nil
+	}
+
+	_, height, err := terminal.GetSize(stdoutFd)
+	if err != nil {
+		return nil, 0, false, nil
+	} else if height == 0 {
+		// the size of some pseudo-terminals is unknown
+		height = 24
+	}
+	state, err := terminal.MakeRaw(stdinFd)
+	if err != nil {
+		return nil, 0, false, nil
+	}
+
+	fmt.Print(pickerEnterScreen)
+	answerData, foundIndex, err := p.Pick(message, os.Stdin, os.Stdout, height)
+	fmt.Print(pickerExitScreen)
+	terminal.Restore(stdinFd, state)
+
+	if err == nil {
+		fmt.Printf("%v: %v\n", message, p.pickerLabel(foundIndex, answerData))
+	}
+	return answerData, foundIndex, true, err
+}
+
+func (p *ProjectList) pickerLabel(ndx int, answer map[string]interface{}) string {
+	answerIndex := strconv.Itoa(ndx + 1)
+	if id, idOk := answer["id"]; idOk {
+		answerIndex = fmt.Sprintf("%s, %s", answerIndex, id)
+	}
+	if aliases := Aliases(answer); len(aliases) > 0 {
+		answerIndex = fmt.Sprintf("%s, %s", answerIndex, strings.Join(aliases, ", "))
+	}
+
+	groupValues := []string{}
+	for level, groupKey := range p.groupByKeys {
+		if value, ok := answer[groupKey]; ok && value != nil {
+			groupValues = append(groupValues, p.coloredString(level, fmt.Sprintf("%v", value)))
+		}
+	}
+
+	otherValues := []string{}
+	for _, k := range utils.MapKeys(answer) {
+		if utils.SliceIncludes(p.hiddenKeys, k) || utils.SliceIncludes(p.groupByKeys, k) {
+			continue
+		}
+		otherValues = append(otherValues, fmt.Sprintf("%v: %v", k, answer[k]))
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%v  %v  %v", color.YellowString(answerIndex), strings.Join(groupValues, " / "), strings.Join(otherValues, ", ")))
+}
+
+// the alias & every scalar answer value (internal maps like `config` are skipped).
+func pickerValues(answer map[string]interface{}) []string {
+	values := []string{}
+	for _, k := range utils.MapKeys(answer) {
+		switch v := answer[k].(type) {
+		case map[string]interface{}, nil:
+			continue
+		case []interface{}:
+			for _, item := range v {
+				values = append(values, strings.ToLower(fmt.Sprintf("%v", item)))
+			}
+		default:
+			values = append(values, strings.ToLower(fmt.Sprintf("%v", v)))
+		}
+	}
+	return values
+}
+
+// fuzzyMatch returns true if the characters of term appear in order (not necessarily adjacent) in value.
+func fuzzyMatch(term string, value string) bool {
+	termRunes := []rune(term)
+	if len(termRunes) == 0 {
+		return true
+	}
+	for _, r := range value {
+		if r == termRunes[0] {
+			termRunes = termRunes[1:]
+			if len(termRunes) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *picker) filter() {
+	terms := strings.Fields(strings.ToLower(string(s.query)))
+	s.matches = []int{}
+	for ndx, item := range s.items {
+		matched := true
+		for _, term := range terms {
+			termMatched := false
+			for _, value := range item.values {
+				if fuzzyMatch(term, value) {
+					termMatched = true
+					break
+				}
+			}
+			if !termMatched {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			s.matches = append(s.matches, ndx)
+		}
+	}
+	if s.cursor >= len(s.matches) {
+		s.cursor = len(s.matches) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+func (s *picker) run(in io.Reader, out io.Writer) (int, error) {
+	buf := make([]byte, 256)
+	for {
+		s.render(out)
+
+		n, err := in.Read(buf)
+		if n > 0 {
+			selected, done, inputErr := s.handleInput(buf[:n])
+			if inputErr != nil {
+				return 0, inputErr
+			} else if done {
+				return selected, nil
+			}
+		}
+		if err == io.EOF {
+			return 0, errors.ProjectListIndexInvalidError("No config selected")
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// handles a chunk of key presses. A terminal in raw mode sends a lone ESC when the escape key is pressed, and the whole
+// sequence (ESC [ A) for arrow keys.
+func (s *picker) handleInput(input []byte) (int, bool, error) {
+	for len(input) > 0 {
+		switch {
+		case input[0] == 0x1b && len(input) >= 3 && (input[1] == '[' || input[1] == 'O'):
+			switch input[2] {
+			case 'A':
+				s.moveCursor(-1)
+			case 'B':
+				s.moveCursor(1)
+			}
+			input = input[3:]
+			continue
+		case input[0] == 0x1b, input[0] == 0x03, input[0] == 0x04:
+			return 0, false, errors.ProjectListIndexInvalidError("No config selected")
+		case input[0] == '\r' || input[0] == '\n':
+			if len(s.matches) > 0 {
+				return s.matches[s.cursor], true, nil
+			}
+		case input[0] == 0x7f || input[0] == 0x08:
+			if len(s.query) > 0 {
+				s.query = s.query[:len(s.query)-1]
+				s.filter()
+			}
+		case input[0] == 0x15:
+			s.query = nil
+			s.filter()
+		case input[0] == 0x10:
+			s.moveCursor(-1)
+		case input[0] == 0x0e || input[0] == '\t':
+			s.moveCursor(1)
+		default:
+			r, size := utf8.DecodeRune(input)
+			if unicode.IsPrint(r) {
+				s.query = append(s.query, r)
+				s.cursor = 0
+				s.filter()
+			}
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return 0, false, nil
+}
+
+func (s *picker) moveCursor(delta int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.cursor = (s.cursor + delta + len(s.matches)) % len(s.matches)
+}
+
+func (s *picker) render(out io.Writer) {
+	// message, query & status lines
+	listHeight := s.height - 3
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	offset := 0
+	if s.cursor >= listHeight {
+		offset = s.cursor - listHeight + 1
+	}
+
+	lines := []string{
+		color.BlueString(s.message),
+		fmt.Sprintf("> %v", string(s.query)),
+	}
+	for ndx := offset; ndx < len(s.matches) && ndx < offset+listHeight; ndx++ {
+		if ndx == s.cursor {
+			lines = append(lines, fmt.Sprintf("\x1b[7m>\x1b[0m %v", s.items[s.matches[ndx]].label))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %v", s.items[s.matches[ndx]].label))
+		}
+	}
+	lines = append(lines, color.HiBlackString("%v/%v configs (type to filter, ↑/↓ to move, enter to select, esc to cancel)", len(s.matches), len(s.items)))
+
+	fmt.Fprint(out, pickerClearScreen+strings.Join(lines, "\r\n"))
+	// leave the terminal cursor at the end of the query
+	fmt.Fprintf(out, "\x1b[2;%dH", len(s.query)+3)
+}
@@ -0,0 +1,60 @@
+package project_test
+
+import (
+	"bytes"
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/stretchr/testify/require"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// simulates key presses, one read per key like a terminal in raw mode.
+type keyReader struct {
+	keys []string
+}
+
+func (k *keyReader) Read(p []byte) (int, error) {
+	if len(k.keys) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, k.keys[0])
+	k.keys = k.keys[1:]
+	return n, nil
+}
+
+func TestProjectList_Pick(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	projList, err := project.CreateProjectListFromProvidedAnswers(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+
+	//test
+	filtered, filteredIndex, filteredErr := projList.Pick("Select a config", &keyReader{keys: []string{"i", "d", "l", " ", "e", "2", "\r"}}, &bytes.Buffer{}, 24)
+	moved, movedIndex, movedErr := projList.Pick("Select a config", &keyReader{keys: []string{"t", "s", "t", "2", "\x7f", "\x7f", "\x7f", "\x7f", "\x1b[B", "\x1b[B", "\x1b[A", "\r"}}, &bytes.Buffer{}, 24)
+	_, _, noMatchErr := projList.Pick("Select a config", &keyReader{keys: []string{"x", "y", "z", "\r", "\x1b"}}, &bytes.Buffer{}, 24)
+	var screen bytes.Buffer
+	_, _, cancelErr := projList.Pick("Select a config", &keyReader{keys: []string{"\x03"}}, &screen, 24)
+
+	//assert
+	require.NoError(t, filteredErr)
+	require.Equal(t, "idle", filtered["shard_type"], "should fuzzy match every term against an answer value")
+	require.Equal(t, "us-east-2", filtered["shard"])
+	expected, _, _ := projList.GetWithIndex(filteredIndex)
+	require.Equal(t, expected, filtered)
+
+	require.NoError(t, movedErr)
+	expected, _, _ = projList.GetWithIndex(1)
+	require.Equal(t, 1, movedIndex, "should move the selection with the arrow keys")
+	require.Equal(t, expected, moved)
+
+	require.IsType(t, errors.ProjectListIndexInvalidError(""), noMatchErr, "should not select anything when no config matches")
+	require.IsType(t, errors.ProjectListIndexInvalidError(""), cancelErr)
+	require.Contains(t, screen.String(), "5/5 configs")
+}
@@ -0,0 +1,117 @@
+package project
+
+import "fmt"
+
+// ProjectEntry is the machine-readable representation of a project in the ProjectList, used by `--output json|yaml`
+type ProjectEntry struct {
+	Index           int                    `json:"index" yaml:"index"`
+	ID              string                 `json:"id,omitempty" yaml:"id,omitempty"`
+	Alias           string                 `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Aliases         []string               `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	ConfigFilePath  string                 `json:"config_filepath" yaml:"config_filepath"`
+	PemFilePath     string                 `json:"pem_filepath,omitempty" yaml:"pem_filepath,omitempty"`
+	Hops            []string               `json:"hops,omitempty" yaml:"hops,omitempty"`
+	CustomFilePaths []string               `json:"custom_filepaths" yaml:"custom_filepaths"`
+	Tags            []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Notes           string                 `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Answers         map[string]interface{} `json:"answers" yaml:"answers"`
+}
+
+// GetAllEntries returns every project, in the same order (and with the same 1-based index) as the printed tree.
+func (p *ProjectList) GetAllEntries() []ProjectEntry {
+	entries := []ProjectEntry{}
+	for ndx, answerData := range p.GetAll() {
+		entries = append(entries, newProjectEntry(ndx, answerData))
+	}
+	return entries
+}
+
+func (p *ProjectList) GetEntryWithAliasOrIndex(aliasOrIndex string) (ProjectEntry, error) {
+	answerData, index, err := p.GetWithAliasOrIndex(aliasOrIndex)
+	if err != nil {
+		return ProjectEntry{}, err
+	}
+	return newProjectEntry(index, answerData), nil
+}
+
+// GetAllEntriesWithAliasOrIndex returns every project matching a selector, or the single project with the number/alias.
+func (p *ProjectList) GetAllEntriesWithAliasOrIndex(aliasOrIndex string) ([]ProjectEntry, error) {
+	answerDataList, indexes, err := p.GetAllWithAliasOrIndex(aliasOrIndex)
+	if err != nil {
+		return nil, err
+	}
+	entries := []ProjectEntry{}
+	for ndx, answerData := range answerDataList {
+		entries = append(entries, newProjectEntry(indexes[ndx], answerData))
+	}
+	return entries, nil
+}
+
+// GetAllEntriesWithTags returns the projects that have every one of the tags (see GetAllWithTags).
+func (p *ProjectList) GetAllEntriesWithTags(aliasOrIndex string, tags []string) ([]ProjectEntry, error) {
+	answerDataList, indexes, err := p.GetAllWithTags(aliasOrIndex, tags)
+	if err != nil {
+		return nil, err
+	}
+	entries := []ProjectEntry{}
+	for ndx, answerData := range answerDataList {
+		entries = append(entries, newProjectEntry(indexes[ndx], answerData))
+	}
+	return entries, nil
+}
+
+func newProjectEntry(index_0based int, answerData map[string]interface{}) ProjectEntry {
+	entry := ProjectEntry{
+		Index:           index_0based + 1,
+		CustomFilePaths: []string{},
+		Answers:         map[string]interface{}{},
+	}
+
+	for k, v := range answerData {
+		switch k {
+		case "id":
+			entry.ID, _ = v.(string)
+		case "alias", "aliases":
+			entry.Aliases = Aliases(answerData)
+			entry.Alias = PrimaryAlias(answerData)
+		case "config":
+			configData, _ := v.(map[string]interface{})
+			entry.ConfigFilePath, _ = configData["filepath"].(string)
+			entry.PemFilePath, _ = configData["pem_filepath"].(string)
+			entry.Hops = HopChain(answerData)
+		case "custom":
+			customDataList, _ := v.([]interface{})
+			for _, customData := range customDataList {
+				customDataMap, _ := customData.(map[string]interface{})
+				if customFilePath, ok := customDataMap["filepath"].(string); ok {
+					entry.CustomFilePaths = append(entry.CustomFilePaths, customFilePath)
+				}
+			}
+		case "tags":
+			entry.Tags = Tags(answerData)
+		case "notes":
+			entry.Notes = Notes(answerData)
+		case "template":
+			continue
+		default:
+			entry.Answers[k] = v
+		}
+	}
+	return entry
+}
+
+// HopChain returns the ordered jump hosts (rendered from the config template `hops`) that must be traversed before
+// reaching the bastion of the project. Returns an empty list when the bastion is reached directly.
+func HopChain(answerData map[string]interface{}) []string {
+	hops := []string{}
+	configData, _ := answerData["config"].(map[string]interface{})
+	switch configHops := configData["hops"].(type) {
+	case []string:
+		hops = append(hops, configHops...)
+	case []interface{}:
+		for _, hop := range configHops {
+			hops = append(hops, fmt.Sprintf("%v", hop))
+		}
+	}
+	return hops
+}
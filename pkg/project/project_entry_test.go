@@ -0,0 +1,76 @@
+package project_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectList_GetAllEntries(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", filepath.Join("testdata", "config_dir"))
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+
+	//test
+	entries := projList.GetAllEntries()
+
+	//assert
+	require.Equal(t, 9, len(entries))
+	require.Equal(t, 1, entries[0].Index, "should use the same 1-based index as the printed tree")
+	require.Equal(t, "/Users/jason/.ssh/drawbridge/prod-app-idle-us-east-1", entries[0].ConfigFilePath)
+	require.Equal(t, "/Users/jason/.ssh/drawbridge/pem/prod/aws-prod.pem", entries[0].PemFilePath)
+	require.Equal(t, []string{}, entries[0].CustomFilePaths)
+	require.Equal(t, "prod", entries[0].Answers["environment"])
+	require.NotContains(t, entries[0].Answers, "config", "rendered template data should not be duplicated in the answers")
+}
+
+func TestProjectList_GetEntryWithAliasOrIndex(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	testConfig.Set("options.config_dir", filepath.Join("testdata", "config_dir"))
+	projList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+
+	//test
+	entry, err := projList.GetEntryWithAliasOrIndex("2")
+	_, invalidErr := projList.GetEntryWithAliasOrIndex("10")
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, 2, entry.Index)
+	require.Equal(t, projList.GetAllEntries()[1], entry)
+	require.Error(t, invalidErr, "should raise an error for an invalid index")
+}
+
+func TestHopChain(t *testing.T) {
+	t.Parallel()
+
+	//test
+	storedHops := project.HopChain(map[string]interface{}{
+		"config": map[string]interface{}{
+			"filepath": "/tmp/drawbridge/prod-app-live-eu-west-1",
+			"hops":     []interface{}{"edge", "regional"},
+		},
+	})
+	directHops := project.HopChain(map[string]interface{}{
+		"config": map[string]interface{}{
+			"filepath": "/tmp/drawbridge/prod-app-idle-us-east-1",
+		},
+	})
+
+	//assert
+	require.Equal(t, []string{"edge", "regional"}, storedHops, "should read hops persisted in the answers file")
+	require.Equal(t, []string{}, directHops, "should return an empty chain when the bastion is reached directly")
+}
@@ -44,6 +44,15 @@ func (p *ProjectList) GetAll() []map[string]interface{} {
 }
 
 func (p *ProjectList) GetWithAliasOrIndex(aliasOrIndex string) (map[string]interface{}, int, error) {
+	if IsSelector(aliasOrIndex) {
+		return p.GetWithSelector(aliasOrIndex)
+	}
+
+	// config IDs never change, so they are preferred over (shifting) indexes
+	if answerData, index, err := p.GetWithID(aliasOrIndex); err == nil {
+		return answerData, index, nil
+	}
+
 	index, err := utils.StringToInt(aliasOrIndex)
 	if err == nil {
 		//successfully parsed aliasOrIndex into an integer. Look up the
@@ -80,7 +89,7 @@ func (p *ProjectList) GetWithAlias(alias string) (map[string]interface{}, int, e
 	}
 
 	for ndx, groupedAnswers := range p.GetAll() {
-		if answerAlias, answerAliasOk := groupedAnswers["alias"]; answerAliasOk && answerAlias.(string) == alias {
+		if utils.SliceIncludes(Aliases(groupedAnswers), alias) {
 			//answer has alias, and matches the requested alias
 			return groupedAnswers, ndx, nil
 		}
@@ -88,34 +97,6 @@ func (p *ProjectList) GetWithAlias(alias string) (map[string]interface{}, int, e
 	return nil, 0, errors.ProjectListIndexInvalidError("Alias not found")
 }
 
-func (p *ProjectList) SetAliasForIndex(index_0based int, alias string) (map[string]interface{}, error) {
-
-	answerYamlFile, err := ioutil.ReadFile(p.projects[index_0based].AnswerFilePath)
-	if err != nil {
-		return nil, errors.ConfigFileMissingError("could not open answerfile for config")
-	}
-
-	answerData := make(map[string]interface{})
-
-	err = yaml.Unmarshal(answerYamlFile, &answerData)
-	if err != nil {
-		return nil, errors.ConfigFileMissingError("could not parse answerfile")
-	}
-
-	if existingAlias, existingAliasOk := answerData["alias"]; existingAliasOk {
-		color.HiYellow("Warning: replacing existing alias (%s) with new value: %s", existingAlias, alias)
-	}
-	answerData["alias"] = alias
-
-	answersFileContent, err := yaml.Marshal(answerData)
-	if err != nil {
-		return nil, err
-	}
-	err = utils.FileWrite(p.projects[index_0based].AnswerFilePath, string(answersFileContent), 0640, false)
-
-	return answerData, err
-}
-
 func (p *ProjectList) Prompt(message string) (map[string]interface{}, int, error) {
 	if p.Length() == 0 {
 		return nil, 0, errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
@@ -125,12 +106,20 @@ func (p *ProjectList) Prompt(message string) (map[string]interface{}, int, error
 		p.initGroups()
 	}
 
+	if answerData, foundIndex, picked, err := p.promptWithPicker(message); picked {
+		return answerData, foundIndex, err
+	}
+
+	// not attached to a terminal, fallback to the numbered tree
 	p.PrintTree("")
 
 	for true {
 
 		//prompt the user to enter a valid choice
-		message := utils.StdinQuery(fmt.Sprintf("%v (%v-%v, alias):", message, 1, p.Length()))
+		message, err := utils.StdinQuery(fmt.Sprintf("%v (%v-%v, alias):", message, 1, p.Length()))
+		if err != nil {
+			return nil, 0, err
+		}
 		answerData, foundIndex, err := p.GetWithAliasOrIndex(message)
 		if err != nil {
 			color.HiRed("ERROR: %v", err)
@@ -149,6 +138,59 @@ func (p *ProjectList) PrintTree(startMessage string) {
 
 // Private functions
 
+// the index is the position in the grouped (printed) list, not the order the answer files were loaded in.
+func (p *ProjectList) projectForIndex(index_0based int) (projectData, error) {
+	answerData, _, err := p.GetWithIndex(index_0based)
+	if err != nil {
+		return projectData{}, err
+	}
+	configData, _ := answerData["config"].(map[string]interface{})
+	for _, project := range p.projects {
+		if len(project.AnswerFilePath) > 0 && project.ConfigFilePath == configData["filepath"] {
+			return project, nil
+		}
+	}
+	return projectData{}, errors.ConfigFileMissingError("could not find the answerfile for config")
+}
+
+// reads the answers file of the config at the (0-based) index, applies the update & writes it back. The update is also
+// applied to the loaded answers, so the changes are visible without reloading the list. Missing config IDs are added.
+func (p *ProjectList) updateAnswerFile(index_0based int, update func(answerData map[string]interface{})) (map[string]interface{}, error) {
+	project, err := p.projectForIndex(index_0based)
+	if err != nil {
+		return nil, err
+	}
+
+	answerYamlFile, err := ioutil.ReadFile(project.AnswerFilePath)
+	if err != nil {
+		return nil, errors.ConfigFileMissingError("could not open answerfile for config")
+	}
+
+	answerData := make(map[string]interface{})
+
+	err = yaml.Unmarshal(answerYamlFile, &answerData)
+	if err != nil {
+		return nil, errors.ConfigFileMissingError("could not parse answerfile")
+	}
+
+	update(answerData)
+	if _, idOk := answerData["id"]; !idOk {
+		answerData["id"] = ConfigID(project.ConfigFilePath)
+	}
+
+	answersFileContent, err := yaml.Marshal(answerData)
+	if err != nil {
+		return nil, err
+	}
+	err = utils.FileWrite(project.AnswerFilePath, string(answersFileContent), 0640, false)
+	if err != nil {
+		return nil, err
+	}
+
+	update(project.Answers)
+	return answerData, nil
+}
+
 func (p *ProjectList) initGroups() {
 	//intialize storage
 	p.groupedAnswers = gabs.New()
@@ -243,10 +285,13 @@ func (p *ProjectList) recursivePopulateGroupListAndTree(level int, parentTree tr
 				p.groupedAnswersList = append(p.groupedAnswersList, answer.(map[string]interface{}))
 
 				//answerStr := printAnswer(len(e.OrderedAnswers), answer.(map[string]interface{}), e.Config.GetStringSlice("options.ui_question_hidden"), e.Config.GetStringSlice("options.ui_group_priority"))
-				alias, aliasOk := answer.(map[string]interface{})["alias"]
+				aliases := Aliases(answer.(map[string]interface{}))
 				answerIndex := strconv.Itoa(len(p.groupedAnswersList))
-				if aliasOk {
-					answerIndex = fmt.Sprintf("%s, %s", answerIndex, alias)
+				if id, idOk := answer.(map[string]interface{})["id"]; idOk {
+					answerIndex = fmt.Sprintf("%s, %s", answerIndex, id)
+				}
+				if len(aliases) > 0 {
+					answerIndex = fmt.Sprintf("%s, %s", answerIndex, strings.Join(aliases, ", "))
 				}
 
 				currentTree.AddMetaNode(
@@ -281,6 +326,16 @@ func (p *ProjectList) answerString(highlightGroupKey string, answer map[string]i
 
 		answerStr = append(answerStr, fmt.Sprintf("%v: %v", k, v))
 	}
+
+	if hops := HopChain(answer); len(hops) > 0 {
+		answerStr = append(answerStr, color.MagentaString("hops: %v", strings.Join(append(hops, "bastion"), " → ")))
+	}
+	if tags := Tags(answer); len(tags) > 0 {
+		answerStr = append(answerStr, color.CyanString("tags: %v", strings.Join(tags, ", ")))
+	}
+	if notes := Notes(answer); len(notes) > 0 {
+		answerStr = append(answerStr, color.CyanString("notes: %v", notes))
+	}
 	return strings.Join(answerStr, ", ")
 }
 
@@ -295,3 +350,30 @@ func (p *ProjectList) coloredString(level int, data string) string {
 		return data
 	}
 }
+
+// returns the string items of a list answer (eg. `aliases` or `tags`)
+func stringList(answerData map[string]interface{}, key string) []string {
+	items := []string{}
+	switch list := answerData[key].(type) {
+	case []string:
+		items = append(items, list...)
+	case []interface{}:
+		for _, item := range list {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	}
+	return items
+}
+
+// stores the list answer, or removes it when it is empty
+func setStringList(answerData map[string]interface{}, key string, items []string) {
+	if len(items) == 0 {
+		delete(answerData, key)
+		return
+	}
+	list := []interface{}{}
+	for _, item := range items {
+		list = append(list, item)
+	}
+	answerData[key] = list
+}
@@ -0,0 +1,132 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// IsSelector returns true if the argument is an answer-value selector (eg. `environment=prod,shard=us-east-1`) rather
+// than a config number or alias.
+func IsSelector(arg string) bool {
+	return strings.Contains(arg, "=")
+}
+
+// ParseSelector parses a comma separated list of `question_key=value` pairs.
+func ParseSelector(selector string) (map[string]string, error) {
+	selectorAnswers := map[string]string{}
+	for _, pair := range strings.Split(selector, ",") {
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 || len(strings.TrimSpace(keyValue[0])) == 0 {
+			return nil, errors.InvalidArgumentsError(fmt.Sprintf("invalid selector `%v`, expected `question_key=value[,question_key=value...]`", selector))
+		}
+		selectorAnswers[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+	return selectorAnswers, nil
+}
+
+// GetAllWithSelector returns every project whose answers match all the `question_key=value` pairs of the selector.
+func (p *ProjectList) GetAllWithSelector(selector string) ([]map[string]interface{}, []int, error) {
+	if p.Length() == 0 {
+		return nil, nil, errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
+	}
+
+	selectorAnswers, err := ParseSelector(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches := []map[string]interface{}{}
+	indexes := []int{}
+	for ndx, answerData := range p.GetAll() {
+		if selectorMatches(selectorAnswers, answerData) {
+			matches = append(matches, answerData)
+			indexes = append(indexes, ndx)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil, errors.ProjectListIndexInvalidError(fmt.Sprintf("No configs match the selector `%v`", selector))
+	}
+	return matches, indexes, nil
+}
+
+// GetWithSelector returns the single project matching the selector, or an error listing the candidates when the
+// selector is ambiguous.
+func (p *ProjectList) GetWithSelector(selector string) (map[string]interface{}, int, error) {
+	matches, indexes, err := p.GetAllWithSelector(selector)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(matches) > 1 {
+		candidates := []string{}
+		for ndx, answerData := range matches {
+			candidates = append(candidates, selectorCandidate(indexes[ndx], answerData))
+		}
+		return nil, 0, errors.ProjectListIndexInvalidError(fmt.Sprintf("The selector `%v` matches %v configs (%v), add more answers to narrow it down", selector, len(matches), strings.Join(candidates, "; ")))
+	}
+	return matches[0], indexes[0], nil
+}
+
+// GetAllWithAliasOrIndex returns every project matching a selector, or the single project with the number/alias. Used
+// by commands that operate on multiple configs.
+func (p *ProjectList) GetAllWithAliasOrIndex(aliasOrIndex string) ([]map[string]interface{}, []int, error) {
+	if IsSelector(aliasOrIndex) {
+		return p.GetAllWithSelector(aliasOrIndex)
+	}
+	answerData, index, err := p.GetWithAliasOrIndex(aliasOrIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []map[string]interface{}{answerData}, []int{index}, nil
+}
+
+func selectorMatches(selectorAnswers map[string]string, answerData map[string]interface{}) bool {
+	for questionKey, selectorValue := range selectorAnswers {
+		if questionKey == "alias" {
+			// matches any of the config aliases
+			if !utils.SliceIncludes(Aliases(answerData), selectorValue) {
+				return false
+			}
+			continue
+		}
+		switch answerValue := answerData[questionKey].(type) {
+		case nil, map[string]interface{}:
+			return false
+		case []interface{}:
+			found := false
+			for _, item := range answerValue {
+				if fmt.Sprintf("%v", item) == selectorValue {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		default:
+			if fmt.Sprintf("%v", answerValue) != selectorValue {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func selectorCandidate(index_0based int, answerData map[string]interface{}) string {
+	candidate := fmt.Sprintf("%v", index_0based+1)
+	if id, idOk := answerData["id"]; idOk {
+		candidate = fmt.Sprintf("%v, %v", candidate, id)
+	}
+	if alias := PrimaryAlias(answerData); len(alias) > 0 {
+		candidate = fmt.Sprintf("%v, %v", candidate, alias)
+	}
+	if configData, ok := answerData["config"].(map[string]interface{}); ok {
+		if configFilePath, ok := configData["filepath"].(string); ok {
+			candidate = fmt.Sprintf("%v: %v", candidate, filepath.Base(configFilePath))
+		}
+	}
+	return candidate
+}
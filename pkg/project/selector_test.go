@@ -0,0 +1,64 @@
+package project_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	t.Parallel()
+
+	//test
+	selectorAnswers, err := project.ParseSelector("environment=prod, shard=us-east-1")
+	_, invalidErr := project.ParseSelector("environment=prod,us-east-1")
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"environment": "prod", "shard": "us-east-1"}, selectorAnswers)
+	require.IsType(t, errors.InvalidArgumentsError(""), invalidErr, "should require a key for every value")
+	require.True(t, project.IsSelector("shard=us-east-1"))
+	require.False(t, project.IsSelector("my_alias"))
+}
+
+func TestProjectList_GetWithSelector(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	testConfig, _ := config.Create()
+	err := testConfig.ReadConfig(filepath.Join("testdata", "valid_configfile_with_answers.yaml"))
+	require.NoError(t, err, "should allow overriding default config template.")
+	projList, err := project.CreateProjectListFromProvidedAnswers(testConfig)
+	require.NoError(t, err, "should correctly load project list")
+
+	//test
+	single, singleIndex, singleErr := projList.GetWithAliasOrIndex("stack_name=test2")
+	_, _, ambiguousErr := projList.GetWithAliasOrIndex("shard=us-east-1")
+	multiple, multipleIndexes, multipleErr := projList.GetAllWithAliasOrIndex("shard=us-east-1,stack_name=tested")
+	_, _, missingErr := projList.GetAllWithSelector("environment=prod")
+	byIndex, _, byIndexErr := projList.GetAllWithAliasOrIndex("1")
+
+	//assert
+	require.NoError(t, singleErr)
+	require.Equal(t, "test2", single["stack_name"])
+	expected, _, _ := projList.GetWithIndex(singleIndex)
+	require.Equal(t, expected, single)
+
+	require.IsType(t, errors.ProjectListIndexInvalidError(""), ambiguousErr, "should not pick one of several matches")
+	require.Contains(t, ambiguousErr.Error(), "matches 3 configs")
+
+	require.NoError(t, multipleErr)
+	require.Len(t, multiple, 2)
+	require.Len(t, multipleIndexes, 2)
+	for _, answerData := range multiple {
+		require.Equal(t, "us-east-1", answerData["shard"])
+		require.Equal(t, "tested", answerData["stack_name"])
+	}
+
+	require.IsType(t, errors.ProjectListIndexInvalidError(""), missingErr)
+	require.NoError(t, byIndexErr)
+	require.Len(t, byIndex, 1, "should still accept config numbers")
+}
@@ -0,0 +1,129 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// TagPattern is the format of config tags (eg. `primary`, `deprecated`)
+const TagPattern = `^[\w][\w-\.]*$`
+
+// Tags returns the tags of a config.
+func Tags(answerData map[string]interface{}) []string {
+	return stringList(answerData, "tags")
+}
+
+// Notes returns the free-form notes of a config, or an empty string.
+func Notes(answerData map[string]interface{}) string {
+	notes, _ := answerData["notes"].(string)
+	return notes
+}
+
+// HasTags returns true if the config has every one of the tags.
+func HasTags(answerData map[string]interface{}, tags []string) bool {
+	configTags := Tags(answerData)
+	for _, tag := range tags {
+		if !utils.SliceIncludes(configTags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTag returns an error if the tag does not match the TagPattern.
+func ValidateTag(tag string) error {
+	if isValid, err := regexp.MatchString(TagPattern, tag); err != nil || !isValid {
+		return errors.InvalidArgumentsError(fmt.Sprintf("invalid tag `%v`, must match pattern: %v", tag, TagPattern))
+	}
+	return nil
+}
+
+// GetAllWithTags returns the projects that have every one of the tags. When aliasOrIndex is provided, only the projects
+// it selects (see GetAllWithAliasOrIndex) are considered.
+func (p *ProjectList) GetAllWithTags(aliasOrIndex string, tags []string) ([]map[string]interface{}, []int, error) {
+	if p.Length() == 0 {
+		return nil, nil, errors.ProjectListEmptyError("No answers found, please call `drawbridge create` first")
+	}
+
+	answerDataList := p.GetAll()
+	indexes := []int{}
+	for ndx := range answerDataList {
+		indexes = append(indexes, ndx)
+	}
+	if len(aliasOrIndex) > 0 {
+		var err error
+		answerDataList, indexes, err = p.GetAllWithAliasOrIndex(aliasOrIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	matches := []map[string]interface{}{}
+	matchIndexes := []int{}
+	for ndx, answerData := range answerDataList {
+		if HasTags(answerData, tags) {
+			matches = append(matches, answerData)
+			matchIndexes = append(matchIndexes, indexes[ndx])
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil, errors.ProjectListIndexInvalidError(fmt.Sprintf("No configs are tagged with `%v`", strings.Join(tags, ", ")))
+	}
+	return matches, matchIndexes, nil
+}
+
+// AddTagsForIndex adds the tags to the config. Tags the config already has are ignored.
+func (p *ProjectList) AddTagsForIndex(index_0based int, tags []string) (map[string]interface{}, error) {
+	for _, tag := range tags {
+		if err := ValidateTag(tag); err != nil {
+			return nil, err
+		}
+	}
+	return p.updateAnswerFile(index_0based, func(answerData map[string]interface{}) {
+		configTags := Tags(answerData)
+		for _, tag := range tags {
+			if !utils.SliceIncludes(configTags, tag) {
+				configTags = append(configTags, tag)
+			}
+		}
+		setStringList(answerData, "tags", configTags)
+	})
+}
+
+// RemoveTagsForIndex removes the tags from the config.
+func (p *ProjectList) RemoveTagsForIndex(index_0based int, tags []string) (map[string]interface{}, error) {
+	answerData, _, err := p.GetWithIndex(index_0based)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if !utils.SliceIncludes(Tags(answerData), tag) {
+			return nil, errors.InvalidArgumentsError(fmt.Sprintf("config (%v) is not tagged with `%v`", index_0based+1, tag))
+		}
+	}
+	return p.updateAnswerFile(index_0based, func(answerData map[string]interface{}) {
+		remainingTags := []string{}
+		for _, tag := range Tags(answerData) {
+			if !utils.SliceIncludes(tags, tag) {
+				remainingTags = append(remainingTags, tag)
+			}
+		}
+		setStringList(answerData, "tags", remainingTags)
+	})
+}
+
+// SetNotesForIndex replaces the notes of the config. Empty notes are removed.
+func (p *ProjectList) SetNotesForIndex(index_0based int, notes string) (map[string]interface{}, error) {
+	notes = strings.TrimSpace(notes)
+	return p.updateAnswerFile(index_0based, func(answerData map[string]interface{}) {
+		if len(notes) == 0 {
+			delete(answerData, "notes")
+		} else {
+			answerData["notes"] = notes
+		}
+	})
+}
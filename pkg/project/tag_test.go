@@ -0,0 +1,67 @@
+package project_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/analogj/drawbridge/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasTags(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	answerData := map[string]interface{}{"tags": []interface{}{"primary", "kafka"}}
+
+	//assert
+	require.True(t, project.HasTags(answerData, []string{"primary"}))
+	require.True(t, project.HasTags(answerData, []string{"primary", "kafka"}))
+	require.False(t, project.HasTags(answerData, []string{"primary", "deprecated"}))
+	require.False(t, project.HasTags(map[string]interface{}{}, []string{"primary"}))
+}
+
+func TestProjectList_TagsAndNotes(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	testConfig, projList := createTempProjectList(t, filepath.Join(parentPath, "config_dir"))
+
+	//test
+	_, err = projList.AddTagsForIndex(0, []string{"primary", "kafka"})
+	require.NoError(t, err)
+	_, err = projList.AddTagsForIndex(1, []string{"primary"})
+	require.NoError(t, err)
+	_, invalidErr := projList.AddTagsForIndex(1, []string{"not valid"})
+	_, err = projList.RemoveTagsForIndex(0, []string{"kafka"})
+	require.NoError(t, err)
+	_, removeMissingErr := projList.RemoveTagsForIndex(0, []string{"kafka"})
+	_, err = projList.SetNotesForIndex(0, " use for kafka only ")
+	require.NoError(t, err)
+
+	reloadedList, err := project.CreateProjectListFromConfigDir(testConfig)
+	require.NoError(t, err)
+	tagged, indexes, taggedErr := reloadedList.GetAllWithTags("", []string{"primary"})
+	_, selectedIndexes, selectedErr := reloadedList.GetAllWithTags("2", []string{"primary"})
+	_, _, missingErr := reloadedList.GetAllWithTags("", []string{"kafka"})
+	entry, entryErr := reloadedList.GetEntryWithAliasOrIndex("1")
+
+	//assert
+	require.Error(t, invalidErr)
+	require.Error(t, removeMissingErr)
+	require.NoError(t, taggedErr)
+	require.Len(t, tagged, 2)
+	require.Equal(t, []int{0, 1}, indexes)
+	require.NoError(t, selectedErr)
+	require.Equal(t, []int{1}, selectedIndexes)
+	require.Error(t, missingErr, "removed tags should not match")
+	require.NoError(t, entryErr)
+	require.Equal(t, []string{"primary"}, entry.Tags)
+	require.Equal(t, "use for kafka only", entry.Notes)
+	require.NotContains(t, entry.Answers, "tags")
+}
@@ -0,0 +1,262 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/analogj/drawbridge/pkg/transport"
+	log "github.com/sirupsen/logrus"
+)
+
+// Route sends traffic for hosts within Domains, and all traffic proxied on ListenAddrs, through the bastion of the
+// drawbridge config at ConfigFilepath.
+type Route struct {
+	Domains        []string
+	ListenAddrs    []string
+	ConfigFilepath string
+}
+
+// Dialer opens a connection to addr (host:port) through the bastion of the route.
+type Dialer func(route Route, addr string) (io.ReadWriteCloser, error)
+
+// Server is a local proxy which accepts both SOCKS5 and HTTP (CONNECT & absolute-URI) requests, and serves Pac at
+// /proxy.pac. Requests on ListenAddr are routed by destination domain, requests on the ListenAddrs of a route always go
+// through that route (these are the addresses the PAC file points the browser at).
+type Server struct {
+	ListenAddr string
+	Pac        string
+	Routes     []Route
+	Dial       Dialer
+
+	listeners []*routeListener
+	mutex     sync.Mutex
+}
+
+// a bound address, and the route all of its requests are sent through (nil to route by domain)
+type routeListener struct {
+	net.Listener
+	route *Route
+}
+
+func (s *Server) ListenAndServe() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	return s.Serve()
+}
+
+// Listen binds ListenAddr and the ListenAddrs of each route, requests are not handled until Serve is called.
+func (s *Server) Listen() error {
+	// most specific domains first, so nested domains can be routed through different bastions.
+	sort.SliceStable(s.Routes, func(i, j int) bool {
+		return longestDomain(s.Routes[i]) > longestDomain(s.Routes[j])
+	})
+
+	// an address shared by several routes (or by the server itself) can only route by domain.
+	addrRoutes := map[string][]int{s.ListenAddr: nil}
+	addrs := []string{s.ListenAddr}
+	for ndx, route := range s.Routes {
+		for _, addr := range route.ListenAddrs {
+			if _, seen := addrRoutes[addr]; !seen {
+				addrs = append(addrs, addr)
+			}
+			addrRoutes[addr] = append(addrRoutes[addr], ndx)
+		}
+	}
+
+	listeners := []*routeListener{}
+	for _, addr := range addrs {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, boundListener := range listeners {
+				boundListener.Close()
+			}
+			return err
+		}
+		var route *Route
+		if addr != s.ListenAddr && len(addrRoutes[addr]) == 1 {
+			route = &s.Routes[addrRoutes[addr][0]]
+		}
+		listeners = append(listeners, &routeListener{Listener: listener, route: route})
+	}
+
+	s.mutex.Lock()
+	s.listeners = listeners
+	s.mutex.Unlock()
+	return nil
+}
+
+// Serve accepts connections on every bound address, until the server is closed.
+func (s *Server) Serve() error {
+	s.mutex.Lock()
+	listeners := s.listeners
+	s.mutex.Unlock()
+
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(listener *routeListener) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					errs <- err
+					return
+				}
+				go s.handle(conn, listener.route)
+			}
+		}(listener)
+	}
+	return <-errs
+}
+
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var closeErr error
+	for _, listener := range s.listeners {
+		if err := listener.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
+
+// Addr returns the address the server is listening on (useful when ListenAddr uses port 0)
+func (s *Server) Addr() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.listeners) == 0 {
+		return s.ListenAddr
+	}
+	return s.listeners[0].Addr().String()
+}
+
+// RouteFor returns the route for the host, or false if the host should be connected to directly.
+func (s *Server) RouteFor(host string) (Route, bool) {
+	host = strings.ToLower(host)
+	for _, route := range s.Routes {
+		for _, domain := range route.Domains {
+			domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return route, true
+			}
+		}
+	}
+	return Route{}, false
+}
+
+func longestDomain(route Route) int {
+	longest := 0
+	for _, domain := range route.Domains {
+		if len(domain) > longest {
+			longest = len(domain)
+		}
+	}
+	return longest
+}
+
+func (s *Server) handle(conn net.Conn, route *Route) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	firstByte, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	if firstByte[0] == socks5Version {
+		err = s.handleSocks5(reader, conn, route)
+	} else {
+		err = s.handleHTTP(reader, conn, route)
+	}
+	if err != nil {
+		log.Debugf("proxy connection from %v failed: %v", conn.RemoteAddr(), err)
+	}
+}
+
+func (s *Server) handleHTTP(reader *bufio.Reader, conn net.Conn, route *Route) error {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return err
+	}
+
+	if req.Method != http.MethodConnect && !req.URL.IsAbs() {
+		return s.servePac(req, conn)
+	}
+
+	addr := req.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		if req.URL.Scheme == "https" {
+			addr = net.JoinHostPort(addr, "443")
+		} else {
+			addr = net.JoinHostPort(addr, "80")
+		}
+	}
+
+	upstream, err := s.dial(route, addr)
+	if err != nil {
+		fmt.Fprintf(conn, "HTTP/1.1 502 Bad Gateway\r\nConnection: close\r\n\r\n")
+		return err
+	}
+	defer upstream.Close()
+
+	if req.Method == http.MethodConnect {
+		fmt.Fprintf(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	} else {
+		// forward the request in origin-form. One request per connection, so keep-alive can't mix up routes.
+		req.Header.Del("Proxy-Connection")
+		req.Header.Del("Proxy-Authorization")
+		req.Close = true
+		if err := req.Write(upstream); err != nil {
+			return err
+		}
+	}
+
+	transport.Pipe(&bufferedConn{Reader: reader, Conn: conn}, upstream)
+	return nil
+}
+
+func (s *Server) servePac(req *http.Request, conn net.Conn) error {
+	status := "200 OK"
+	body := s.Pac
+	if req.URL.Path != "/proxy.pac" {
+		status = "404 Not Found"
+		body = "not found\n"
+	}
+	_, err := fmt.Fprintf(conn, "HTTP/1.1 %v\r\nContent-Type: application/x-ns-proxy-autoconfig\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%v", status, len(body), body)
+	return err
+}
+
+// dials through the route, or the route matching the destination domain when route is nil.
+func (s *Server) dial(route *Route, addr string) (io.ReadWriteCloser, error) {
+	if route != nil {
+		log.Infof("%v -> %v", addr, route.ConfigFilepath)
+		return s.Dial(*route, addr)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if route, ok := s.RouteFor(host); ok {
+		log.Infof("%v -> %v", addr, route.ConfigFilepath)
+		return s.Dial(route, addr)
+	}
+	log.Debugf("%v -> DIRECT", addr)
+	return net.Dial("tcp", addr)
+}
+
+// bufferedConn makes sure any bytes already buffered while parsing the request are not lost.
+type bufferedConn struct {
+	*bufio.Reader
+	net.Conn
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.Reader.Read(p)
+}
@@ -0,0 +1,179 @@
+package proxy_test
+
+import (
+	"bufio"
+	"github.com/analogj/drawbridge/pkg/proxy"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// returns a server whose routed connections are answered by an in-memory echo server. Requests proxied on listenAddr
+// are always routed through the `/tmp/test` config.
+func startTestServer(t *testing.T, dialed chan string, listenAddr string) *proxy.Server {
+	server := &proxy.Server{
+		ListenAddr: "127.0.0.1:0",
+		Pac:        "function FindProxyForURL(url, host){ return \"DIRECT\"; }\n",
+		Routes: []proxy.Route{
+			{Domains: []string{"internal.example.com"}, ConfigFilepath: "/tmp/prod"},
+			{Domains: []string{"test.internal.example.com"}, ListenAddrs: []string{listenAddr}, ConfigFilepath: "/tmp/test"},
+		},
+		Dial: func(route proxy.Route, addr string) (io.ReadWriteCloser, error) {
+			dialed <- route.ConfigFilepath + " " + addr
+			client, upstream := net.Pipe()
+			go func() {
+				io.Copy(upstream, upstream)
+				upstream.Close()
+			}()
+			return client, nil
+		},
+	}
+
+	require.NoError(t, server.Listen())
+	go server.Serve()
+	return server
+}
+
+// returns a local address that is not in use
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestServer_RouteFor(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	server := startTestServer(t, make(chan string, 1), freeAddr(t))
+	defer server.Close()
+
+	//test
+	prodRoute, prodOk := server.RouteFor("db.internal.example.com")
+	testRoute, testOk := server.RouteFor("db.test.internal.example.com")
+	_, directOk := server.RouteFor("www.google.com")
+
+	//assert
+	require.True(t, prodOk)
+	require.Equal(t, "/tmp/prod", prodRoute.ConfigFilepath)
+	require.True(t, testOk)
+	require.Equal(t, "/tmp/test", testRoute.ConfigFilepath, "should prefer the most specific domain")
+	require.False(t, directOk, "should not route unknown domains")
+}
+
+func TestServer_Pac(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	server := startTestServer(t, make(chan string, 1), freeAddr(t))
+	defer server.Close()
+
+	//test
+	resp, err := http.Get("http://" + server.Addr() + "/proxy.pac")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, server.Pac, string(body), "should serve the rendered PAC file")
+}
+
+func TestServer_HttpConnect(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	dialed := make(chan string, 1)
+	server := startTestServer(t, dialed, freeAddr(t))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	//test
+	_, err = conn.Write([]byte("CONNECT db.internal.example.com:5432 HTTP/1.1\r\nHost: db.internal.example.com:5432\r\n\r\n"))
+	require.NoError(t, err)
+	status, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	reader.ReadString('\n')
+
+	conn.Write([]byte("ping\n"))
+	echo, err := reader.ReadString('\n')
+
+	//assert
+	require.True(t, strings.HasPrefix(status, "HTTP/1.1 200"), "should establish the tunnel")
+	require.Equal(t, "/tmp/prod db.internal.example.com:5432", <-dialed)
+	require.NoError(t, err)
+	require.Equal(t, "ping\n", echo, "should pipe data through the routed connection")
+}
+
+func TestServer_Socks5(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	dialed := make(chan string, 1)
+	server := startTestServer(t, dialed, freeAddr(t))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	//test
+	conn.Write([]byte{0x05, 0x01, 0x00})
+	greeting := make([]byte, 2)
+	_, err = io.ReadFull(conn, greeting)
+	require.NoError(t, err)
+
+	domain := "db.test.internal.example.com"
+	request := append([]byte{0x05, 0x01, 0x00, 0x03, byte(len(domain))}, []byte(domain)...)
+	request = append(request, 0x00, 0x16)
+	conn.Write(request)
+	reply := make([]byte, 10)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+
+	conn.Write([]byte("ping"))
+	echo := make([]byte, 4)
+	_, err = io.ReadFull(conn, echo)
+
+	//assert
+	require.Equal(t, []byte{0x05, 0x00}, greeting, "should accept unauthenticated clients")
+	require.Equal(t, byte(0x00), reply[1], "should report success")
+	require.Equal(t, "/tmp/test db.test.internal.example.com:22", <-dialed)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(echo))
+}
+
+func TestServer_RouteListenAddr(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	dialed := make(chan string, 1)
+	listenAddr := freeAddr(t)
+	server := startTestServer(t, dialed, listenAddr)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", listenAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	//test
+	_, err = conn.Write([]byte("CONNECT db.internal.example.com:5432 HTTP/1.1\r\nHost: db.internal.example.com:5432\r\n\r\n"))
+	require.NoError(t, err)
+	status, err := reader.ReadString('\n')
+
+	//assert
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(status, "HTTP/1.1 200"), "should establish the tunnel")
+	require.Equal(t, "/tmp/test db.internal.example.com:5432", <-dialed, "should route every request on the address through its config")
+}
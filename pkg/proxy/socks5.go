@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/transport"
+)
+
+// https://tools.ietf.org/html/rfc1928
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthUnacceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded          = 0x00
+	socks5ReplyHostUnreachable    = 0x04
+	socks5ReplyCmdNotSupported    = 0x07
+	socks5ReplyAddrTypeNotSupport = 0x08
+)
+
+func (s *Server) handleSocks5(reader *bufio.Reader, conn net.Conn, route *Route) error {
+	// greeting: VER NMETHODS METHODS...
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return err
+	}
+
+	noAuth := false
+	for _, method := range methods {
+		if method == socks5AuthNone {
+			noAuth = true
+		}
+	}
+	if !noAuth {
+		conn.Write([]byte{socks5Version, socks5AuthUnacceptable})
+		return errors.ProxyError("socks5 client does not support unauthenticated connections")
+	}
+	if _, err := conn.Write([]byte{socks5Version, socks5AuthNone}); err != nil {
+		return err
+	}
+
+	// request: VER CMD RSV ATYP DST.ADDR DST.PORT
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil {
+		return err
+	}
+	if request[1] != socks5CmdConnect {
+		writeSocks5Reply(conn, socks5ReplyCmdNotSupported)
+		return errors.ProxyError(fmt.Sprintf("unsupported socks5 command: %v", request[1]))
+	}
+
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if request[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return err
+		}
+		host = net.IP(ip).String()
+	case socks5AddrDomain:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		domain := make([]byte, length)
+		if _, err := io.ReadFull(reader, domain); err != nil {
+			return err
+		}
+		host = string(domain)
+	default:
+		writeSocks5Reply(conn, socks5ReplyAddrTypeNotSupport)
+		return errors.ProxyError(fmt.Sprintf("unsupported socks5 address type: %v", request[3]))
+	}
+
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(reader, portBytes); err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+
+	upstream, err := s.dial(route, addr)
+	if err != nil {
+		writeSocks5Reply(conn, socks5ReplyHostUnreachable)
+		return err
+	}
+	defer upstream.Close()
+
+	if err := writeSocks5Reply(conn, socks5ReplySucceeded); err != nil {
+		return err
+	}
+	transport.Pipe(&bufferedConn{Reader: reader, Conn: conn}, upstream)
+	return nil
+}
+
+func writeSocks5Reply(conn net.Conn, reply byte) error {
+	// the bound address is not meaningful for a tunneled connection, so always report 0.0.0.0:0
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,165 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+// Header is the first line of an asciicast v2 file (https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md)
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the terminal output of a session as asciicast v2 output ("o") events.
+// It is safe for concurrent use, so it can record both stdout & stderr.
+type Recorder struct {
+	out       io.Writer
+	startedAt time.Time
+	pending   []byte
+	lock      sync.Mutex
+}
+
+// NewRecorder writes the asciicast header to out, and returns a Recorder for the session's output.
+func NewRecorder(out io.Writer, header Header) (*Recorder, error) {
+	startedAt := time.Now()
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = startedAt.Unix()
+	}
+	headerLine, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(append(headerLine, '\n')); err != nil {
+		return nil, err
+	}
+	return &Recorder{out: out, startedAt: startedAt}, nil
+}
+
+// Write records p as an output event. Incomplete UTF-8 sequences at the end of p are held back until the next write,
+// since events must be valid JSON strings.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data := append(r.pending, p...)
+	complete := len(data)
+	for ndx := len(data) - 1; ndx >= 0 && ndx >= len(data)-utf8.UTFMax; ndx-- {
+		if utf8.RuneStart(data[ndx]) {
+			if !utf8.FullRune(data[ndx:]) {
+				complete = ndx
+			}
+			break
+		}
+	}
+	r.pending = append([]byte{}, data[complete:]...)
+	if complete == 0 {
+		return len(p), nil
+	}
+
+	if err := r.writeEvent(string(data[:complete])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes any incomplete UTF-8 sequence left over from the last write.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	err := r.writeEvent(string(r.pending))
+	r.pending = nil
+	return err
+}
+
+func (r *Recorder) writeEvent(data string) error {
+	event, err := json.Marshal([]interface{}{time.Since(r.startedAt).Seconds(), "o", data})
+	if err != nil {
+		return err
+	}
+	_, err = r.out.Write(append(event, '\n'))
+	return err
+}
+
+// ReadHeader parses the header line of an asciicast v2 recording.
+func ReadHeader(in io.Reader) (Header, error) {
+	line, err := bufio.NewReader(in).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return Header{}, err
+	}
+	return parseHeader(line)
+}
+
+func parseHeader(line []byte) (Header, error) {
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil {
+		return header, errors.AnswerFormatError(fmt.Sprintf("not an asciicast recording: %v", err))
+	}
+	if header.Version != 2 {
+		return header, errors.AnswerFormatError(fmt.Sprintf("unsupported asciicast version: %v", header.Version))
+	}
+	return header, nil
+}
+
+// Play writes the output events of an asciicast v2 recording to out, with the original timing divided by speed. Pauses
+// are capped at idleLimit (if positive).
+func Play(in io.Reader, out io.Writer, speed float64, idleLimit time.Duration) error {
+	if speed <= 0 {
+		speed = 1
+	}
+	reader := bufio.NewReader(in)
+	headerLine, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if _, err := parseHeader(headerLine); err != nil {
+		return err
+	}
+
+	previous := 0.0
+	for lineNumber := 2; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var event []interface{}
+			if jsonErr := json.Unmarshal(line, &event); jsonErr != nil || len(event) != 3 {
+				return errors.AnswerFormatError(fmt.Sprintf("invalid asciicast event on line %v", lineNumber))
+			}
+			elapsed, _ := event[0].(float64)
+			eventType, _ := event[1].(string)
+			data, _ := event[2].(string)
+			if eventType == "o" {
+				delay := time.Duration((elapsed - previous) / speed * float64(time.Second))
+				if idleLimit > 0 && delay > idleLimit {
+					delay = idleLimit
+				}
+				if delay > 0 {
+					time.Sleep(delay)
+				}
+				previous = elapsed
+				if _, err := io.WriteString(out, data); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
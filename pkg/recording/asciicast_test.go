@@ -0,0 +1,83 @@
+package recording_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/recording"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	var castData bytes.Buffer
+	recorder, err := recording.NewRecorder(&castData, recording.Header{Width: 120, Height: 40, Title: "prod → bastion"})
+	require.NoError(t, err)
+	snowman := []byte("☃")
+
+	//test
+	_, err = recorder.Write([]byte("$ ls\r\n"))
+	require.NoError(t, err)
+	_, err = recorder.Write(append([]byte("it's cold "), snowman[:1]...))
+	require.NoError(t, err)
+	_, err = recorder.Write(snowman[1:])
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+
+	//assert
+	lines := strings.Split(strings.TrimSpace(castData.String()), "\n")
+	require.Len(t, lines, 4)
+	header, err := recording.ReadHeader(strings.NewReader(lines[0]))
+	require.NoError(t, err)
+	require.Equal(t, 2, header.Version)
+	require.Equal(t, 120, header.Width)
+	require.Equal(t, "prod → bastion", header.Title)
+	require.NotZero(t, header.Timestamp)
+
+	var event []interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	require.Equal(t, "o", event[1])
+	require.Equal(t, "$ ls\r\n", event[2])
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	require.Equal(t, "it's cold ", event[2])
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &event))
+	require.Equal(t, "☃", event[2], "should hold back incomplete UTF-8 sequences until they are complete")
+}
+
+func TestPlay(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	castData := `{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "hello "]
+[0.2, "i", "ignored input"]
+[60.5, "o", "world"]
+`
+	var output bytes.Buffer
+
+	//test
+	startedAt := time.Now()
+	err := recording.Play(strings.NewReader(castData), &output, 2, 10*time.Millisecond)
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "hello world", output.String())
+	require.True(t, time.Since(startedAt) < time.Second, "should cap pauses at the idle limit")
+}
+
+func TestPlay_Invalid(t *testing.T) {
+	t.Parallel()
+
+	//test
+	versionErr := recording.Play(strings.NewReader(`{"version": 1, "width": 80, "height": 24, "stdout": []}`), &bytes.Buffer{}, 1, 0)
+	eventErr := recording.Play(strings.NewReader("{\"version\": 2, \"width\": 80, \"height\": 24}\nnot json\n"), &bytes.Buffer{}, 1, 0)
+
+	//assert
+	require.IsType(t, errors.AnswerFormatError(""), versionErr, "should only support asciicast v2")
+	require.Contains(t, eventErr.Error(), "line 2")
+}
@@ -0,0 +1,81 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// Extension of asciicast recordings
+const Extension = ".cast"
+
+const timestampFormat = "20060102T150405"
+
+// Recording is a session recording, stored at `<config_dir>/recordings/<name>/<host>/<timestamp>.cast`
+type Recording struct {
+	Filepath string `json:"filepath" yaml:"filepath"`
+	// the config alias, or the config file name when the config has no alias
+	Name      string    `json:"name" yaml:"name"`
+	Host      string    `json:"host" yaml:"host"`
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+}
+
+// Dir returns the absolute path of the recordings directory.
+func Dir(appConfig config.Interface) (string, error) {
+	return utils.ExpandPath(filepath.Join(appConfig.GetString("options.config_dir"), "recordings"))
+}
+
+// NewFilepath returns the path of a new recording for the session.
+func NewFilepath(recordingsDir string, name string, host string, startedAt time.Time) string {
+	return filepath.Join(recordingsDir, pathComponent(name), pathComponent(host), startedAt.Format(timestampFormat)+Extension)
+}
+
+// List returns the recordings in the recordings directory, oldest first. A missing directory has no recordings.
+func List(recordingsDir string) ([]Recording, error) {
+	recordings := []Recording{}
+	err := filepath.Walk(recordingsDir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != Extension {
+			return nil
+		}
+		relPath, err := filepath.Rel(recordingsDir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(relPath), "/")
+		if len(parts) != 3 {
+			return nil
+		}
+		startedAt, err := time.ParseInLocation(timestampFormat, strings.TrimSuffix(parts[2], Extension), time.Local)
+		if err != nil {
+			return nil
+		}
+		recordings = append(recordings, Recording{Filepath: path, Name: parts[0], Host: parts[1], StartedAt: startedAt})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.Before(recordings[j].StartedAt)
+	})
+	return recordings, nil
+}
+
+// aliases & hostnames become directory names, so they must not contain path separators.
+func pathComponent(value string) string {
+	value = strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(value)
+	if len(value) == 0 || value == "." || value == ".." {
+		return "_"
+	}
+	return value
+}
@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v2"
+)
+
+// the master password for the encrypted file store can be provided via this environment variable (for headless use)
+const PasswordEnvVar = "DRAWBRIDGE_SECRET_STORE_PASSWORD"
+
+// FileStore stores secrets in a single AES-256-GCM encrypted file, with the key derived (scrypt) from a master password.
+// It works everywhere, including headless linux machines without a Secret Service keyring.
+type FileStore struct {
+	FilePath string
+
+	// Password returns the master password. confirm is true when the store is being created for the first time.
+	// Defaults to the DRAWBRIDGE_SECRET_STORE_PASSWORD environment variable, falling back to a prompt.
+	Password func(confirm bool) (string, error)
+
+	password string
+}
+
+// encryptedFile is the on-disk (yaml) format of the FileStore
+type encryptedFile struct {
+	Version    int    `yaml:"version"`
+	Salt       []byte `yaml:"salt"`
+	Nonce      []byte `yaml:"nonce"`
+	Ciphertext []byte `yaml:"ciphertext"`
+}
+
+func NewFileStore(filePath string) *FileStore {
+	return &FileStore{FilePath: filePath, Password: promptPassword}
+}
+
+func (s *FileStore) Name() string {
+	return fmt.Sprintf("encrypted file (%v)", s.FilePath)
+}
+
+func (s *FileStore) Get(key string) (string, bool, error) {
+	if !utils.FileExists(s.FilePath) {
+		return "", false, nil
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	secret, found := secrets[key]
+	return secret, found, nil
+}
+
+func (s *FileStore) Set(key string, secret string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = secret
+	return s.save(secrets)
+}
+
+func (s *FileStore) Delete(key string) error {
+	if !utils.FileExists(s.FilePath) {
+		return nil
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, found := secrets[key]; !found {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *FileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	if !utils.FileExists(s.FilePath) {
+		return secrets, nil
+	}
+
+	fileData, err := ioutil.ReadFile(s.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedFile
+	if err := yaml.Unmarshal(fileData, &file); err != nil {
+		return nil, errors.SecretStoreError(fmt.Sprintf("could not parse %v: %v", s.FilePath, err))
+	}
+
+	aead, err := s.cipher(file.Salt, false)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		// do not keep a password that could not decrypt the store.
+		s.password = ""
+		return nil, errors.SecretStoreError(fmt.Sprintf("could not decrypt %v, the password is incorrect", s.FilePath))
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, errors.SecretStoreError(fmt.Sprintf("could not parse %v: %v", s.FilePath, err))
+	}
+	return secrets, nil
+}
+
+func (s *FileStore) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	// a new salt & nonce are generated every time the store is written.
+	file := encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := io.ReadFull(rand.Reader, file.Salt); err != nil {
+		return err
+	}
+	aead, err := s.cipher(file.Salt, !utils.FileExists(s.FilePath))
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	fileData, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.FilePath), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.FilePath, fileData, 0600)
+}
+
+// derives the encryption key from the master password & salt
+func (s *FileStore) cipher(salt []byte, confirm bool) (cipher.AEAD, error) {
+	if len(s.password) == 0 {
+		password, err := s.Password(confirm)
+		if err != nil {
+			return nil, err
+		}
+		if len(password) == 0 {
+			return nil, errors.SecretStoreError("the secret store password cannot be empty")
+		}
+		s.password = password
+	}
+
+	key, err := scrypt.Key([]byte(s.password), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func promptPassword(confirm bool) (string, error) {
+	if password := os.Getenv(PasswordEnvVar); len(password) > 0 {
+		return password, nil
+	}
+
+	if !confirm {
+		return utils.StdinQueryPassword("Please enter the password for the drawbridge secret store:")
+	}
+
+	password, err := utils.StdinQueryPassword("Please enter a new password for the drawbridge secret store:")
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := utils.StdinQueryPassword("Please confirm the password:")
+	if err != nil {
+		return "", err
+	}
+	if password != confirmation {
+		return "", errors.SecretStoreError("the passwords do not match")
+	}
+	return password, nil
+}
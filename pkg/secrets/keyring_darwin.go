@@ -0,0 +1,64 @@
+// +build darwin
+
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+const keyringDescription = "macOS Keychain"
+
+// `security` exits with this status when the keychain item does not exist
+const securityItemNotFound = 44
+
+func keyringAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+// keyringStore stores secrets as generic passwords in the login keychain, using the `security` binary.
+// Secrets are base64 encoded, since `security` hex encodes multi-line passwords.
+type keyringStore struct{}
+
+func (s *keyringStore) Name() string {
+	return keyringDescription
+}
+
+func (s *keyringStore) Get(key string) (string, bool, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", serviceName, "-a", key, "-w").Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == securityItemNotFound {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.SecretStoreError(fmt.Sprintf("could not read %v from the %v: %v", key, keyringDescription, err))
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+	if err != nil {
+		return "", false, errors.SecretStoreError(fmt.Sprintf("could not decode %v from the %v: %v", key, keyringDescription, err))
+	}
+	return string(secret), true, nil
+}
+
+func (s *keyringStore) Set(key string, secret string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(secret))
+	output, err := exec.Command("security", "add-generic-password", "-U", "-s", serviceName, "-a", key, "-w", encoded).CombinedOutput()
+	if err != nil {
+		return errors.SecretStoreError(fmt.Sprintf("could not store %v in the %v: %v %v", key, keyringDescription, err, strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	output, err := exec.Command("security", "delete-generic-password", "-s", serviceName, "-a", key).CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == securityItemNotFound {
+		return nil
+	} else if err != nil {
+		return errors.SecretStoreError(fmt.Sprintf("could not delete %v from the %v: %v %v", key, keyringDescription, err, strings.TrimSpace(string(output))))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// +build linux
+
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+const keyringDescription = "Secret Service keyring"
+
+// the Secret Service (gnome-keyring, KWallet) is only reachable from a desktop session with a D-Bus session bus.
+// Headless machines fall back to the encrypted file store.
+func keyringAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil && len(os.Getenv("DBUS_SESSION_BUS_ADDRESS")) > 0
+}
+
+// keyringStore stores secrets in the Secret Service keyring using the `secret-tool` binary (libsecret).
+// Secrets are base64 encoded, so multi-line PEM contents survive the round trip.
+type keyringStore struct{}
+
+func (s *keyringStore) Name() string {
+	return keyringDescription
+}
+
+func (s *keyringStore) Get(key string) (string, bool, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", serviceName, "account", key).Output()
+	if _, ok := err.(*exec.ExitError); ok && len(output) == 0 {
+		// secret-tool exits with status 1 (and no output) when the item does not exist
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.SecretStoreError(fmt.Sprintf("could not read %v from the %v: %v", key, keyringDescription, err))
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+	if err != nil {
+		return "", false, errors.SecretStoreError(fmt.Sprintf("could not decode %v from the %v: %v", key, keyringDescription, err))
+	}
+	return string(secret), true, nil
+}
+
+func (s *keyringStore) Set(key string, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%v %v", serviceName, key), "service", serviceName, "account", key)
+	// the secret is read from stdin, so it never shows up in the process list
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString([]byte(secret)))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.SecretStoreError(fmt.Sprintf("could not store %v in the %v: %v %v", key, keyringDescription, err, strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	output, err := exec.Command("secret-tool", "clear", "service", serviceName, "account", key).CombinedOutput()
+	if err != nil {
+		return errors.SecretStoreError(fmt.Sprintf("could not delete %v from the %v: %v %v", key, keyringDescription, err, strings.TrimSpace(string(output))))
+	}
+	return nil
+}
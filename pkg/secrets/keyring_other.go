@@ -0,0 +1,34 @@
+// +build !linux,!darwin
+
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+const keyringDescription = "OS keyring"
+
+// OS keyrings are only supported on macOS & linux. Other platforms use the encrypted file store.
+func keyringAvailable() bool {
+	return false
+}
+
+type keyringStore struct{}
+
+func (s *keyringStore) Name() string {
+	return keyringDescription
+}
+
+func (s *keyringStore) Get(key string) (string, bool, error) {
+	return "", false, errors.DependencyMissingError(fmt.Sprintf("the %v is not supported on this platform", keyringDescription))
+}
+
+func (s *keyringStore) Set(key string, secret string) error {
+	return errors.DependencyMissingError(fmt.Sprintf("the %v is not supported on this platform", keyringDescription))
+}
+
+func (s *keyringStore) Delete(key string) error {
+	return errors.DependencyMissingError(fmt.Sprintf("the %v is not supported on this platform", keyringDescription))
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// values supported by `options.secret_store`
+const (
+	// use the OS keyring when available, falling back to the encrypted file store
+	StoreAuto    = "auto"
+	StoreKeyring = "keyring"
+	StoreFile    = "file"
+	StoreNone    = "none"
+)
+
+// the keyring service (and file store name) that drawbridge secrets are stored under.
+const serviceName = "drawbridge"
+
+// Store is a pluggable backend used to persist PEM key contents & passphrases outside of the pem_dir.
+type Store interface {
+	// Name returns a human readable description of the backend
+	Name() string
+	// Get returns the secret stored for the key. found is false when the key does not exist.
+	Get(key string) (secret string, found bool, err error)
+	Set(key string, secret string) error
+	Delete(key string) error
+}
+
+// New returns the secret store configured by `options.secret_store`
+func New(appConfig config.Interface) (Store, error) {
+	storeType := appConfig.GetString("options.secret_store")
+	switch storeType {
+	case StoreNone:
+		return &noneStore{}, nil
+	case StoreKeyring:
+		if !keyringAvailable() {
+			return nil, errors.DependencyMissingError(fmt.Sprintf("no OS keyring is available on this machine (%v)", keyringDescription))
+		}
+		return &keyringStore{}, nil
+	case StoreFile:
+		return newFileStoreFromConfig(appConfig)
+	case StoreAuto, "":
+		if keyringAvailable() {
+			return &keyringStore{}, nil
+		}
+		return newFileStoreFromConfig(appConfig)
+	default:
+		return nil, errors.ConfigValidationError(fmt.Sprintf("unknown secret_store: %v", storeType))
+	}
+}
+
+// PemKey is the secret key used to store the contents of the PEM file at pemFilepath
+func PemKey(pemFilepath string) string {
+	return "pem:" + pemFilepath
+}
+
+// PassphraseKey is the secret key used to store the passphrase of the PEM file at pemFilepath
+func PassphraseKey(pemFilepath string) string {
+	return "passphrase:" + pemFilepath
+}
+
+func newFileStoreFromConfig(appConfig config.Interface) (Store, error) {
+	configDir, err := utils.ExpandPath(appConfig.GetString("options.config_dir"))
+	if err != nil {
+		return nil, err
+	}
+	return NewFileStore(filepath.Join(configDir, "secrets", serviceName+".enc")), nil
+}
+
+// noneStore is used when `options.secret_store` is disabled
+type noneStore struct{}
+
+func (s *noneStore) Name() string {
+	return "disabled"
+}
+
+func (s *noneStore) Get(key string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (s *noneStore) Set(key string, secret string) error {
+	return errors.SecretStoreError("the secret store is disabled (options.secret_store = none)")
+}
+
+func (s *noneStore) Delete(key string) error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package secrets_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/config"
+	"github.com/analogj/drawbridge/pkg/secrets"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNew_File(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.config_dir", "/tmp/drawbridge")
+	configData.Set("options.secret_store", secrets.StoreFile)
+
+	//test
+	secretStore, err := secrets.New(configData)
+
+	//assert
+	require.NoError(t, err)
+	require.IsType(t, &secrets.FileStore{}, secretStore)
+	require.Equal(t, "/tmp/drawbridge/secrets/drawbridge.enc", secretStore.(*secrets.FileStore).FilePath)
+}
+
+func TestNew_None(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	configData, err := config.Create()
+	require.NoError(t, err)
+	configData.Set("options.secret_store", secrets.StoreNone)
+
+	//test
+	secretStore, err := secrets.New(configData)
+	require.NoError(t, err)
+	_, found, getErr := secretStore.Get(secrets.PemKey("/tmp/test.pem"))
+	setErr := secretStore.Set(secrets.PemKey("/tmp/test.pem"), "secret")
+
+	//assert
+	require.NoError(t, getErr)
+	require.False(t, found)
+	require.Error(t, setErr, "should not be able to store secrets when the secret store is disabled")
+}
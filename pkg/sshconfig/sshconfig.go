@@ -0,0 +1,138 @@
+package sshconfig
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/utils"
+)
+
+// Minimal parser for the ssh_config files rendered by Drawbridge config templates.
+// Only the subset of ssh_config(5) semantics required by drawbridge is supported:
+// `Host` blocks with glob patterns (including negation), and `Key value`/`Key=value` options.
+// `Match` and `Include` directives are ignored.
+
+type HostBlock struct {
+	Patterns []string
+	Options  []Option
+}
+
+type Option struct {
+	Key   string //always lowercase
+	Value string
+}
+
+type Config struct {
+	Blocks []HostBlock
+}
+
+func ParseFile(configFilepath string) (*Config, error) {
+	configFilepath, err := utils.ExpandPath(configFilepath)
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := os.Open(configFilepath)
+	if err != nil {
+		return nil, err
+	}
+	defer configFile.Close()
+
+	return Parse(configFile)
+}
+
+func Parse(r io.Reader) (*Config, error) {
+	// options specified before the first Host block apply to all hosts.
+	config := &Config{Blocks: []HostBlock{{Patterns: []string{"*"}}}}
+	current := &config.Blocks[0]
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value := splitLine(line)
+		if len(key) == 0 {
+			continue
+		}
+
+		if key == "host" {
+			config.Blocks = append(config.Blocks, HostBlock{Patterns: strings.Fields(value)})
+			current = &config.Blocks[len(config.Blocks)-1]
+			continue
+		}
+		current.Options = append(current.Options, Option{Key: key, Value: value})
+	}
+	return config, scanner.Err()
+}
+
+// Get returns the first value for key that applies to hostAlias, mirroring ssh's "first obtained value wins" rule.
+func (c *Config) Get(hostAlias string, key string) string {
+	values := c.GetAll(hostAlias, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// GetAll returns every value for key that applies to hostAlias (eg. multiple IdentityFile or LocalForward entries)
+func (c *Config) GetAll(hostAlias string, key string) []string {
+	key = strings.ToLower(key)
+	values := []string{}
+	for _, block := range c.Blocks {
+		if !block.Matches(hostAlias) {
+			continue
+		}
+		for _, option := range block.Options {
+			if option.Key == key {
+				values = append(values, option.Value)
+			}
+		}
+	}
+	return values
+}
+
+// HasHost returns true if any non-global Host block explicitly matches hostAlias
+func (c *Config) HasHost(hostAlias string) bool {
+	for _, block := range c.Blocks[1:] {
+		if block.Matches(hostAlias) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *HostBlock) Matches(hostAlias string) bool {
+	matched := false
+	for _, pattern := range b.Patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		if ok, _ := path.Match(pattern, hostAlias); ok {
+			if negated {
+				//a negated match always excludes the block.
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+func splitLine(line string) (string, string) {
+	sepIndex := strings.IndexAny(line, " \t=")
+	if sepIndex == -1 {
+		return strings.ToLower(line), ""
+	}
+	key := strings.ToLower(line[:sepIndex])
+	value := strings.TrimLeft(line[sepIndex:], " \t")
+	value = strings.TrimPrefix(value, "=")
+	value = strings.TrimSpace(value)
+	value = strings.Trim(value, `"`)
+	return key, value
+}
@@ -0,0 +1,39 @@
+package sshconfig_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	//test
+	config, err := sshconfig.ParseFile(filepath.Join("testdata", "prod-app-idle-us-east-1"))
+
+	//assert
+	require.NoError(t, err, "should correctly parse rendered config file")
+	require.Equal(t, "bastion1.idle.us-east-1.appexample.com", config.Get("bastion", "Hostname"))
+	require.Equal(t, "cloud-user", config.Get("bastion", "user"))
+	require.Equal(t, "/dev/null", config.Get("bastion", "UserKnownHostsFile"), "should support Key=Value syntax")
+	require.Equal(t, "yes", config.Get("bastion", "ForwardAgent"), "should inherit global options")
+	require.Equal(t, []string{"localhost:24680 localhost:8080"}, config.GetAll("bastion", "LocalForward"))
+	require.Equal(t, "INFO", config.Get("bastion+database-1", "LogLevel"), "should match wildcard host patterns")
+	require.Equal(t, "", config.Get("bastion", "LogLevel"))
+	require.True(t, config.HasHost("bastion"))
+	require.False(t, config.HasHost("database-1.in"))
+}
+
+func TestHostBlock_Matches(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	block := sshconfig.HostBlock{Patterns: []string{"*.in", "!secret.in"}}
+
+	//assert
+	require.True(t, block.Matches("database-1.in"))
+	require.False(t, block.Matches("secret.in"), "negated patterns should exclude the host")
+	require.False(t, block.Matches("bastion"))
+}
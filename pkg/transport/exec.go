@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+// ExecDial opens a connection to addr (host:port) through the host alias using the system ssh binary (`ssh -W`).
+// The returned connection is the stdin/stdout of the ssh process, closing it terminates the process.
+func ExecDial(configFilepath string, hostAlias string, addr string) (io.ReadWriteCloser, error) {
+	sshBin, lookErr := exec.LookPath("ssh")
+	if lookErr != nil {
+		return nil, errors.DependencyMissingError("ssh is missing")
+	}
+
+	cmd := exec.Command(sshBin, "-F", configFilepath, "-o", "BatchMode=yes", "-W", addr, hostAlias)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &execConn{Reader: stdout, WriteCloser: stdin, cmd: cmd}, nil
+}
+
+type execConn struct {
+	io.Reader
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *execConn) Close() error {
+	c.WriteCloser.Close()
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+	return nil
+}
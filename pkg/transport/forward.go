@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// LocalForward is a parsed ssh_config `LocalForward [bind_address:]port host:hostport` entry
+type LocalForward struct {
+	ListenAddr string
+	RemoteAddr string
+}
+
+func ParseLocalForward(value string) (LocalForward, error) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return LocalForward{}, errors.ConfigValidationError(fmt.Sprintf("invalid LocalForward entry: %q", value))
+	}
+
+	listenAddr := parts[0]
+	if !strings.Contains(listenAddr, ":") {
+		listenAddr = net.JoinHostPort("localhost", listenAddr)
+	}
+	return LocalForward{ListenAddr: listenAddr, RemoteAddr: parts[1]}, nil
+}
+
+// Forward connects to the host alias and serves every LocalForward defined for it, until the context is cancelled or
+// the ssh connection drops (detected with keepalive requests). connected (optional) is called once the connection and
+// all listeners are up.
+func (t *NativeTransport) Forward(ctx context.Context, hostAlias string, connected func()) error {
+	forwards := []LocalForward{}
+	for _, value := range t.Config.GetAll(hostAlias, "localforward") {
+		forward, err := ParseLocalForward(value)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, forward)
+	}
+	if len(forwards) == 0 {
+		return errors.ConfigValidationError(fmt.Sprintf("no LocalForward entries defined for %v in %v", hostAlias, t.ConfigFilepath))
+	}
+
+	connection, err := t.Dial(hostAlias)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	listeners := []net.Listener{}
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+
+	for _, forward := range forwards {
+		listener, err := net.Listen("tcp", forward.ListenAddr)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, listener)
+		log.Infof("Forwarding %v -> %v", forward.ListenAddr, forward.RemoteAddr)
+		go acceptForwards(listener, connection, forward.RemoteAddr)
+	}
+	if connected != nil {
+		connected()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- connection.Wait()
+	}()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-done:
+			if err == nil {
+				err = errors.TransportError("connection closed by remote host")
+			}
+			return err
+		case <-keepalive.C:
+			if _, _, err := connection.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func acceptForwards(listener net.Listener, connection *Connection, remoteAddr string) {
+	for {
+		localConn, err := listener.Accept()
+		if err != nil {
+			//listener closed
+			return
+		}
+
+		go func() {
+			defer localConn.Close()
+			remoteConn, err := connection.Dial("tcp", remoteAddr)
+			if err != nil {
+				log.Warnf("Could not open forward to %v: %v", remoteAddr, err)
+				return
+			}
+			defer remoteConn.Close()
+			Pipe(localConn, remoteConn)
+		}()
+	}
+}
+
+// Pipe copies data in both directions until either side is closed.
+func Pipe(a io.ReadWriteCloser, b io.ReadWriteCloser) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
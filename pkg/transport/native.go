@@ -0,0 +1,273 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/analogj/drawbridge/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// the host alias every Drawbridge config template must define.
+const BastionHostAlias = "bastion"
+
+// NativeTransport is an in-process alternative to exec'ing the `ssh`/`scp` binaries.
+// It reads the rendered Drawbridge config file, dials the bastion and then opens a direct-tcpip channel through it
+// to reach internal hosts.
+type NativeTransport struct {
+	ConfigFilepath string
+	Config         *sshconfig.Config
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func New(configFilepath string) (*NativeTransport, error) {
+	config, err := sshconfig.ParseFile(configFilepath)
+	if err != nil {
+		return nil, err
+	}
+	if !config.HasHost(BastionHostAlias) {
+		return nil, errors.ConfigValidationError(fmt.Sprintf("config file at %v does not define a `Host %v` entry", configFilepath, BastionHostAlias))
+	}
+
+	return &NativeTransport{
+		ConfigFilepath: configFilepath,
+		Config:         config,
+		Stdin:          os.Stdin,
+		Stdout:         os.Stdout,
+		Stderr:         os.Stderr,
+	}, nil
+}
+
+// Connection wraps the chain of ssh clients required to reach a host. Closing it tears down the whole chain.
+type Connection struct {
+	*ssh.Client
+	chain []*ssh.Client
+}
+
+func (c *Connection) Close() error {
+	var firstErr error
+	for i := len(c.chain) - 1; i >= 0; i-- {
+		if err := c.chain[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Dial connects to the host alias (`bastion`, `<host>.in` or `bastion+<host>`), routing through the bastion for
+// internal hosts.
+func (t *NativeTransport) Dial(hostAlias string) (*Connection, error) {
+	connection, err := t.DialBastion()
+	if err != nil || hostAlias == BastionHostAlias {
+		return connection, err
+	}
+
+	targetClient, err := t.DialThrough(connection.Client, hostAlias)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	connection.Client = targetClient
+	connection.chain = append(connection.chain, targetClient)
+	return connection, nil
+}
+
+// DialBastion connects to the bastion host, first hopping through each jump host in the bastion's ProxyJump option
+// (in order). The connection can be shared by multiple DialThrough calls.
+func (t *NativeTransport) DialBastion() (*Connection, error) {
+	connection := &Connection{chain: []*ssh.Client{}}
+	for _, hop := range append(t.JumpHosts(), BastionHostAlias) {
+		hopClient, err := t.dialHop(connection.Client, hop)
+		if err != nil {
+			connection.Close()
+			return nil, err
+		}
+		connection.Client = hopClient
+		connection.chain = append(connection.chain, hopClient)
+	}
+	return connection, nil
+}
+
+// DialThrough connects to an internal host alias through an existing bastion client. The caller must close the
+// returned client before the bastion.
+func (t *NativeTransport) DialThrough(bastionClient *ssh.Client, hostAlias string) (*ssh.Client, error) {
+	return t.dialHop(bastionClient, hostAlias)
+}
+
+// JumpHosts returns the ordered jump hosts listed in the bastion's ProxyJump option (rendered from the config
+// template `hops`).
+func (t *NativeTransport) JumpHosts() []string {
+	return JumpHosts(t.Config)
+}
+
+// JumpHosts returns the ordered jump hosts listed in the bastion's ProxyJump option of a parsed config.
+func JumpHosts(config *sshconfig.Config) []string {
+	jumpHosts := []string{}
+	proxyJump := config.Get(BastionHostAlias, "proxyjump")
+	if strings.ToLower(proxyJump) == "none" {
+		return jumpHosts
+	}
+	for _, jumpHost := range strings.Split(proxyJump, ",") {
+		if jumpHost = strings.TrimSpace(jumpHost); len(jumpHost) > 0 {
+			jumpHosts = append(jumpHosts, jumpHost)
+		}
+	}
+	return jumpHosts
+}
+
+// dials the hop (a host alias or [user@]host[:port]) directly, or through the previous hop's client if provided.
+func (t *NativeTransport) dialHop(previousClient *ssh.Client, hop string) (*ssh.Client, error) {
+	user, hostAlias, port := splitJumpHost(hop)
+	hopConfig, err := t.clientConfig(hostAlias)
+	if err != nil {
+		return nil, err
+	}
+	if len(user) > 0 {
+		hopConfig.User = user
+	}
+	hopAddr := t.HostAddress(hostAlias)
+	if len(port) > 0 {
+		hostname, _, _ := net.SplitHostPort(hopAddr)
+		hopAddr = net.JoinHostPort(hostname, port)
+	}
+
+	if previousClient == nil {
+		log.Debugf("Dialing %v", hopAddr)
+		return ssh.Dial("tcp", hopAddr, hopConfig)
+	}
+
+	log.Debugf("Opening direct-tcpip channel to %v", hopAddr)
+	hopConn, err := previousClient.Dial("tcp", hopAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(hopConn, hopAddr, hopConfig)
+	if err != nil {
+		hopConn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, chans, reqs), nil
+}
+
+// HostAddress returns the host:port that should be dialed for the host alias.
+func (t *NativeTransport) HostAddress(hostAlias string) string {
+	hostname := t.Config.Get(hostAlias, "hostname")
+	if len(hostname) == 0 {
+		hostname = internalHostname(hostAlias)
+	} else {
+		hostname = strings.Replace(hostname, "%h", internalHostname(hostAlias), -1)
+	}
+
+	port := t.Config.Get(hostAlias, "port")
+	if len(port) == 0 {
+		port = "22"
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+func (t *NativeTransport) clientConfig(hostAlias string) (*ssh.ClientConfig, error) {
+	hostKeyCallback, err := t.hostKeyCallback(hostAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	user := t.Config.Get(hostAlias, "user")
+	if len(user) == 0 {
+		user = os.Getenv("USER")
+	}
+
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            t.authMethods(hostAlias),
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}, nil
+}
+
+// authenticate with any keys loaded in the ssh-agent (connect adds the config's PEM key there), falling back to
+// unencrypted IdentityFile keys referenced by the config.
+func (t *NativeTransport) authMethods(hostAlias string) []ssh.AuthMethod {
+	signers := []ssh.Signer{}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); len(socket) > 0 {
+		if agentConn, err := net.Dial("unix", socket); err == nil {
+			agentSigners, err := agent.NewClient(agentConn).Signers()
+			if err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		} else {
+			log.Debugf("Could not connect to ssh-agent: %v", err)
+		}
+	}
+
+	for _, identityFile := range t.Config.GetAll(hostAlias, "identityfile") {
+		identityFile, err := utils.ExpandPath(identityFile)
+		if err != nil {
+			continue
+		}
+		keyData, err := ioutil.ReadFile(identityFile)
+		if err != nil {
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(keyData)
+		if err != nil {
+			log.Debugf("Skipping IdentityFile %v: %v", identityFile, err)
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	return []ssh.AuthMethod{ssh.PublicKeys(signers...)}
+}
+
+// honor the StrictHostKeyChecking & UserKnownHostsFile options set by the config template.
+func (t *NativeTransport) hostKeyCallback(hostAlias string) (ssh.HostKeyCallback, error) {
+	if strings.ToLower(t.Config.Get(hostAlias, "stricthostkeychecking")) == "no" {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	knownHostsFile := t.Config.Get(hostAlias, "userknownhostsfile")
+	if len(knownHostsFile) == 0 {
+		knownHostsFile = "~/.ssh/known_hosts"
+	}
+	knownHostsFile, err := utils.ExpandPath(knownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	return knownhosts.New(knownHostsFile)
+}
+
+// `<host>.in` and `bastion+<host>` are the two aliases drawbridge uses to address internal hosts.
+func internalHostname(hostAlias string) string {
+	if strings.HasPrefix(hostAlias, BastionHostAlias+"+") {
+		return strings.TrimPrefix(hostAlias, BastionHostAlias+"+")
+	}
+	return strings.TrimSuffix(hostAlias, ".in")
+}
+
+// splits a ProxyJump entry ([user@]host[:port]) into its parts. user & port are empty when not specified.
+func splitJumpHost(jumpHost string) (string, string, string) {
+	user := ""
+	if ndx := strings.LastIndex(jumpHost, "@"); ndx >= 0 {
+		user = jumpHost[:ndx]
+		jumpHost = jumpHost[ndx+1:]
+	}
+	if host, port, err := net.SplitHostPort(jumpHost); err == nil {
+		return user, host, port
+	}
+	return user, jumpHost, ""
+}
@@ -0,0 +1,27 @@
+package transport_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestJumpHosts(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	config, err := sshconfig.Parse(strings.NewReader("Host bastion\n  Hostname bastion.example.com\n\nHost bastion\n  ProxyJump edge, cloud-user@regional.example.com:2222\n"))
+	require.NoError(t, err)
+	directConfig, err := sshconfig.Parse(strings.NewReader("Host bastion\n  Hostname bastion.example.com\n  ProxyJump none\n"))
+	require.NoError(t, err)
+
+	//test
+	jumpHosts := transport.JumpHosts(config)
+	directJumpHosts := transport.JumpHosts(directConfig)
+
+	//assert
+	require.Equal(t, []string{"edge", "cloud-user@regional.example.com:2222"}, jumpHosts, "should return the jump hosts in order")
+	require.Equal(t, []string{}, directJumpHosts, "ProxyJump none should disable the hop chain")
+}
@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+)
+
+// DefaultPreflightTimeout limits each stage (DNS, TCP & SSH banner) of the bastion pre-flight check.
+const DefaultPreflightTimeout = 5 * time.Second
+
+const preflightMaxBannerLines = 10
+
+// PreflightResult is the outcome of checking that the first host on the way to the bastion is reachable.
+type PreflightResult struct {
+	// the host alias (or [user@]host[:port] jump host) that is dialed first: the first `hops` entry, or the bastion
+	Host      string   `json:"host" yaml:"host"`
+	Address   string   `json:"address" yaml:"address"`
+	Resolved  []string `json:"resolved,omitempty" yaml:"resolved,omitempty"`
+	Banner    string   `json:"banner,omitempty" yaml:"banner,omitempty"`
+	LatencyMs int64    `json:"latency_ms" yaml:"latency_ms"`
+	// set when the check was skipped, eg. because the host is reached through a ProxyCommand
+	Skipped string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+// Preflight checks that the first host on the way to the bastion resolves (DNS), accepts TCP connections and responds
+// with an SSH banner. Returns a BastionUnreachableError describing the failing stage.
+func (t *NativeTransport) Preflight(timeout time.Duration) (PreflightResult, error) {
+	host := BastionHostAlias
+	if jumpHosts := t.JumpHosts(); len(jumpHosts) > 0 {
+		host = jumpHosts[0]
+	}
+	_, hostAlias, port := splitJumpHost(host)
+
+	address := t.HostAddress(hostAlias)
+	if len(port) > 0 {
+		hostname, _, _ := net.SplitHostPort(address)
+		address = net.JoinHostPort(hostname, port)
+	}
+	result := PreflightResult{Host: host, Address: address}
+
+	if proxyCommand := t.Config.Get(hostAlias, "proxycommand"); len(proxyCommand) > 0 && strings.ToLower(proxyCommand) != "none" {
+		result.Skipped = fmt.Sprintf("%v is reached through a ProxyCommand", host)
+		return result, nil
+	}
+
+	hostname, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return result, errors.BastionUnreachableError(fmt.Sprintf("invalid address for %v (%v): %v", host, address, err))
+	}
+
+	startedAt := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	result.Resolved, err = net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return result, errors.BastionUnreachableError(fmt.Sprintf("could not resolve %v (%v): %v", host, hostname, err))
+	}
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return result, errors.BastionUnreachableError(fmt.Sprintf("could not connect to %v (%v): %v", host, address, err))
+	}
+	defer conn.Close()
+
+	// the server sends its identification string (`SSH-2.0-...`) as soon as the connection is established. It may be
+	// preceded by a few other lines (RFC 4253 section 4.2).
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	reader := bufio.NewReader(conn)
+	for lines := 0; !strings.HasPrefix(result.Banner, "SSH-"); lines++ {
+		if lines >= preflightMaxBannerLines {
+			return result, errors.BastionUnreachableError(fmt.Sprintf("%v (%v) is not an SSH server, it responded with %q", host, address, result.Banner))
+		}
+		banner, err := reader.ReadString('\n')
+		if err != nil {
+			return result, errors.BastionUnreachableError(fmt.Sprintf("no SSH banner received from %v (%v): %v", host, address, err))
+		}
+		result.Banner = strings.TrimSpace(banner)
+	}
+	result.LatencyMs = time.Since(startedAt).Milliseconds()
+	return result, nil
+}
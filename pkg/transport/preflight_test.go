@@ -0,0 +1,115 @@
+package transport_test
+
+import (
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/analogj/drawbridge/pkg/sshconfig"
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/stretchr/testify/require"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listens on a random local port, writing the response to every connection.
+func listenWithResponse(t *testing.T, response string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(response))
+			conn.Close()
+		}
+	}()
+	return listener
+}
+
+func preflightTransport(t *testing.T, configContent string) *transport.NativeTransport {
+	config, err := sshconfig.Parse(strings.NewReader(configContent))
+	require.NoError(t, err)
+	return &transport.NativeTransport{Config: config}
+}
+
+func TestNativeTransport_Preflight(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	listener := listenWithResponse(t, "Welcome\r\nSSH-2.0-OpenSSH_test\r\n")
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+	nativeTransport := preflightTransport(t, fmt.Sprintf("Host bastion\n  Hostname 127.0.0.1\n  Port %v\n", port))
+
+	//test
+	result, err := nativeTransport.Preflight(time.Second)
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "bastion", result.Host)
+	require.Equal(t, fmt.Sprintf("127.0.0.1:%v", port), result.Address)
+	require.Equal(t, "SSH-2.0-OpenSSH_test", result.Banner, "should skip lines sent before the SSH banner")
+}
+
+func TestNativeTransport_Preflight_FirstHop(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	listener := listenWithResponse(t, "SSH-2.0-OpenSSH_test\r\n")
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+	nativeTransport := preflightTransport(t, fmt.Sprintf("Host edge\n  Hostname 127.0.0.1\n\nHost bastion\n  Hostname 192.0.2.1\n  ProxyJump edge:%v,regional\n", port))
+
+	//test
+	result, err := nativeTransport.Preflight(time.Second)
+
+	//assert
+	require.NoError(t, err, "should only check the first jump host, the bastion is reached through it")
+	require.Equal(t, fmt.Sprintf("edge:%v", port), result.Host)
+	require.Equal(t, fmt.Sprintf("127.0.0.1:%v", port), result.Address)
+}
+
+func TestNativeTransport_Preflight_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	httpListener := listenWithResponse(t, "HTTP/1.1 400 Bad Request\r\n\r\n")
+	defer httpListener.Close()
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+
+	httpTransport := preflightTransport(t, fmt.Sprintf("Host bastion\n  Hostname 127.0.0.1\n  Port %v\n", httpListener.Addr().(*net.TCPAddr).Port))
+	closedTransport := preflightTransport(t, fmt.Sprintf("Host bastion\n  Hostname 127.0.0.1\n  Port %v\n", closedPort))
+	dnsTransport := preflightTransport(t, "Host bastion\n  Hostname bastion.invalid\n")
+
+	//test
+	_, httpErr := httpTransport.Preflight(time.Second)
+	_, closedErr := closedTransport.Preflight(time.Second)
+	_, dnsErr := dnsTransport.Preflight(time.Second)
+
+	//assert
+	require.IsType(t, errors.BastionUnreachableError(""), httpErr, "should fail when the host is not an SSH server")
+	require.IsType(t, errors.BastionUnreachableError(""), closedErr, "should fail when the port is closed")
+	require.Contains(t, closedErr.Error(), "could not connect")
+	require.IsType(t, errors.BastionUnreachableError(""), dnsErr, "should fail when the hostname does not resolve")
+	require.Contains(t, dnsErr.Error(), "could not resolve")
+}
+
+func TestNativeTransport_Preflight_ProxyCommand(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	nativeTransport := preflightTransport(t, "Host bastion\n  Hostname bastion.invalid\n  ProxyCommand ssh -W %h:%p gateway\n")
+
+	//test
+	result, err := nativeTransport.Preflight(time.Second)
+
+	//assert
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Skipped, "should skip hosts reached through a ProxyCommand")
+}
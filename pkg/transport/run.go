@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RunThrough executes command on an internal host alias through an existing bastion connection, streaming the
+// remote output to stdout & stderr. Returns the remote exit status (-1 if the command could not be started).
+func (t *NativeTransport) RunThrough(bastion *Connection, hostAlias string, command string, stdout io.Writer, stderr io.Writer) (int, error) {
+	client, err := t.DialThrough(bastion.Client, hostAlias)
+	if err != nil {
+		return -1, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	err = session.Run(command)
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return exitErr.ExitStatus(), nil
+	} else if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
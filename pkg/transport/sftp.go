@@ -0,0 +1,205 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/analogj/drawbridge/pkg/errors"
+	"github.com/pkg/sftp"
+)
+
+// File copies use the SFTP subsystem of the internal host's sshd, so no `scp`/`sftp` binary is required on either
+// side of the connection.
+
+// Download copies remotePath (a file, or a directory when recursive is set) from the host alias to localPath.
+func (t *NativeTransport) Download(hostAlias string, remotePath string, localPath string, recursive bool) error {
+	connection, err := t.Dial(hostAlias)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	client, err := newSftpClient(connection)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return SftpDownload(client, remotePath, localPath, recursive)
+}
+
+// Upload copies localPath (a file, or a directory when recursive is set) to remotePath on the host alias.
+func (t *NativeTransport) Upload(hostAlias string, localPath string, remotePath string, recursive bool) error {
+	connection, err := t.Dial(hostAlias)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	client, err := newSftpClient(connection)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return SftpUpload(client, localPath, remotePath, recursive)
+}
+
+// opens the `sftp` subsystem on a new session of the connection
+func newSftpClient(connection *Connection) (*sftp.Client, error) {
+	session, err := connection.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		return nil, errors.TransportError(fmt.Sprintf("the remote host does not support sftp: %v", err))
+	}
+	remoteIn, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	remoteOut, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	client, err := sftp.NewClientPipe(remoteOut, remoteIn)
+	if err != nil {
+		session.Close()
+		return nil, errors.TransportError(fmt.Sprintf("could not start sftp session: %v", err))
+	}
+	return client, nil
+}
+
+// SftpDownload copies remotePath from the sftp server to localPath. Like scp, the copy is renamed to localPath, unless
+// localPath is an existing directory.
+func SftpDownload(client *sftp.Client, remotePath string, localPath string, recursive bool) error {
+	remotePath = sftpPath(remotePath)
+	remoteInfo, err := client.Stat(remotePath)
+	if err != nil {
+		return errors.TransportError(fmt.Sprintf("could not stat remote path %v: %v", remotePath, err))
+	}
+	if remoteInfo.IsDir() && !recursive {
+		return errors.InvalidArgumentsError(fmt.Sprintf("%v is a directory, recursive copy is required", remotePath))
+	}
+
+	if localInfo, err := os.Stat(localPath); err == nil && localInfo.IsDir() {
+		localPath = filepath.Join(localPath, remoteInfo.Name())
+	}
+	return downloadPath(client, remotePath, remoteInfo, localPath)
+}
+
+// SftpUpload copies localPath to remotePath on the sftp server. Like scp, the copy is renamed to remotePath, unless
+// remotePath is an existing directory.
+func SftpUpload(client *sftp.Client, localPath string, remotePath string, recursive bool) error {
+	localInfo, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if localInfo.IsDir() && !recursive {
+		return errors.InvalidArgumentsError(fmt.Sprintf("%v is a directory, recursive copy is required", localPath))
+	}
+
+	remotePath = sftpPath(remotePath)
+	if remoteInfo, err := client.Stat(remotePath); err == nil && remoteInfo.IsDir() {
+		remotePath = path.Join(remotePath, localInfo.Name())
+	}
+	return uploadPath(client, localPath, localInfo, remotePath)
+}
+
+func downloadPath(client *sftp.Client, remotePath string, remoteInfo os.FileInfo, localPath string) error {
+	if !remoteInfo.IsDir() {
+		remoteFile, err := client.Open(remotePath)
+		if err != nil {
+			return errors.TransportError(fmt.Sprintf("could not open remote file %v: %v", remotePath, err))
+		}
+		defer remoteFile.Close()
+
+		localFile, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, remoteInfo.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer localFile.Close()
+
+		_, err = remoteFile.WriteTo(localFile)
+		return err
+	}
+
+	if err := os.MkdirAll(localPath, remoteInfo.Mode().Perm()|0700); err != nil {
+		return err
+	}
+	entries, err := client.ReadDir(remotePath)
+	if err != nil {
+		return errors.TransportError(fmt.Sprintf("could not list remote directory %v: %v", remotePath, err))
+	}
+	for _, entry := range entries {
+		// Skip symlinks, like utils.CopyDir
+		if entry.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if entry.Name() == "." || entry.Name() == ".." || strings.Contains(entry.Name(), "/") {
+			return errors.TransportError(fmt.Sprintf("refusing unsafe remote file name: %q", entry.Name()))
+		}
+		if err := downloadPath(client, path.Join(remotePath, entry.Name()), entry, filepath.Join(localPath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func uploadPath(client *sftp.Client, localPath string, localInfo os.FileInfo, remotePath string) error {
+	if !localInfo.IsDir() {
+		localFile, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer localFile.Close()
+
+		remoteFile, err := client.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+		if err != nil {
+			return errors.TransportError(fmt.Sprintf("could not create remote file %v: %v", remotePath, err))
+		}
+		defer remoteFile.Close()
+
+		if _, err := io.Copy(remoteFile, localFile); err != nil {
+			return err
+		}
+		return client.Chmod(remotePath, localInfo.Mode().Perm())
+	}
+
+	if err := client.MkdirAll(remotePath); err != nil {
+		return errors.TransportError(fmt.Sprintf("could not create remote directory %v: %v", remotePath, err))
+	}
+	if err := client.Chmod(remotePath, localInfo.Mode().Perm()); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(localPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		// Skip symlinks, like utils.CopyDir
+		if entry.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if err := uploadPath(client, filepath.Join(localPath, entry.Name()), entry, path.Join(remotePath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sftp paths are relative to the remote home directory, so `~/` is dropped rather than expanded by a remote shell.
+func sftpPath(remotePath string) string {
+	if remotePath == "~" {
+		return "."
+	}
+	return strings.TrimPrefix(remotePath, "~/")
+}
@@ -0,0 +1,110 @@
+package transport_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/analogj/drawbridge/pkg/transport"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+// connects an sftp client to an in-process sftp server (serving the local filesystem), as the remote sshd would.
+func newTestSftpClient(t *testing.T) *sftp.Client {
+	clientToServerReader, clientToServerWriter := io.Pipe()
+	serverToClientReader, serverToClientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{clientToServerReader, serverToClientWriter})
+	require.NoError(t, err)
+	go func() {
+		// the server stops when the client closes its end, then hangs up so the client stops too
+		server.Serve()
+		serverToClientWriter.Close()
+	}()
+
+	client, err := sftp.NewClientPipe(serverToClientReader, clientToServerWriter)
+	require.NoError(t, err)
+	return client
+}
+
+func TestSftpUploadDownload_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+
+	srcPath := filepath.Join(parentPath, "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcPath, "nested"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, "config.yaml"), []byte("key: value\n"), 0640))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcPath, "nested", "hotfix.sh"), []byte("#!/bin/sh\necho fixed\n"), 0755))
+	remotePath := filepath.Join(parentPath, "remote")
+	destPath := filepath.Join(parentPath, "dest")
+
+	client := newTestSftpClient(t)
+	defer client.Close()
+
+	//test
+	uploadErr := transport.SftpUpload(client, srcPath, remotePath, true)
+	downloadErr := transport.SftpDownload(client, remotePath, destPath, true)
+
+	//assert
+	require.NoError(t, uploadErr, "should upload all files")
+	require.NoError(t, downloadErr, "should download all files")
+
+	content, err := ioutil.ReadFile(filepath.Join(destPath, "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "key: value\n", string(content))
+
+	nestedInfo, err := os.Stat(filepath.Join(destPath, "nested", "hotfix.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), nestedInfo.Mode().Perm(), "should preserve file permissions")
+}
+
+func TestSftpDownload_IntoDirectory(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	parentPath, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	remoteFilePath := filepath.Join(parentPath, "app.log")
+	require.NoError(t, ioutil.WriteFile(remoteFilePath, []byte("started\n"), 0644))
+	destDir := filepath.Join(parentPath, "dest")
+	require.NoError(t, os.MkdirAll(destDir, 0755))
+
+	client := newTestSftpClient(t)
+	defer client.Close()
+
+	//test
+	err = transport.SftpDownload(client, remoteFilePath, destDir, false)
+	dirErr := transport.SftpDownload(client, destDir, filepath.Join(parentPath, "copy"), false)
+
+	//assert
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(destDir, "app.log"))
+	require.NoError(t, err, "should keep the remote file name when downloading into a directory")
+	require.Equal(t, "started\n", string(content))
+	require.Error(t, dirErr, "should require recursive copy for directories")
+}
+
+func TestSftpDownload_Missing(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	client := newTestSftpClient(t)
+	defer client.Close()
+
+	//test
+	err := transport.SftpDownload(client, filepath.Join(os.TempDir(), "drawbridge-missing-file"), filepath.Join(os.TempDir(), "missing"), false)
+
+	//assert
+	require.Error(t, err, "should surface errors reported by the sftp server")
+}
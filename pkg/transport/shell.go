@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Shell opens an interactive login shell on the host alias, with a PTY when stdin is a terminal.
+func (t *NativeTransport) Shell(hostAlias string) error {
+	connection, err := t.Dial(hostAlias)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	session, err := connection.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = t.Stdin
+	session.Stdout = t.Stdout
+	session.Stderr = t.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer terminal.Restore(fd, state)
+
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+
+		term := os.Getenv("TERM")
+		if len(term) == 0 {
+			term = "xterm-256color"
+		}
+
+		err = session.RequestPty(term, height, width, ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		})
+		if err != nil {
+			return err
+		}
+
+		stopWatching := watchWindowSize(fd, session)
+		defer stopWatching()
+	}
+
+	if err := session.Shell(); err != nil {
+		return err
+	}
+	return session.Wait()
+}
@@ -0,0 +1,31 @@
+// +build linux darwin
+
+package transport
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// forward local terminal resizes to the remote PTY.
+func watchWindowSize(fd int, session *ssh.Session) func() {
+	sigwinch := make(chan os.Signal, 1)
+	signal.Notify(sigwinch, syscall.SIGWINCH)
+
+	go func() {
+		for range sigwinch {
+			if width, height, err := terminal.GetSize(fd); err == nil {
+				session.WindowChange(height, width)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigwinch)
+		close(sigwinch)
+	}
+}
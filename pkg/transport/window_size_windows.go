@@ -0,0 +1,12 @@
+// +build windows
+
+package transport
+
+import (
+	"golang.org/x/crypto/ssh"
+)
+
+// windows has no SIGWINCH, the remote PTY keeps the size it was created with.
+func watchWindowSize(fd int, session *ssh.Session) func() {
+	return func() {}
+}
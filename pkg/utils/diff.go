@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DiffLines returns a unified-style line diff between two strings, with `contextLines` of unchanged lines around each
+// change. Lines are prefixed with "+", "-" or " ". Returns an empty slice when the contents are identical.
+func DiffLines(before string, after string, contextLines int) []string {
+	if before == after {
+		return []string{}
+	}
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+
+	// longest common subsequence table, config files are small enough for the O(n*m) approach.
+	lcs := make([][]int, len(beforeLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(afterLines)+1)
+	}
+	for i := len(beforeLines) - 1; i >= 0; i-- {
+		for j := len(afterLines) - 1; j >= 0; j-- {
+			if beforeLines[i] == afterLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []string{}
+	i, j := 0, 0
+	for i < len(beforeLines) || j < len(afterLines) {
+		if i < len(beforeLines) && j < len(afterLines) && beforeLines[i] == afterLines[j] {
+			ops = append(ops, " "+beforeLines[i])
+			i++
+			j++
+		} else if i < len(beforeLines) && (j == len(afterLines) || lcs[i+1][j] >= lcs[i][j+1]) {
+			ops = append(ops, "-"+beforeLines[i])
+			i++
+		} else {
+			ops = append(ops, "+"+afterLines[j])
+			j++
+		}
+	}
+
+	// only keep changed lines & their context
+	keep := make([]bool, len(ops))
+	for ndx, op := range ops {
+		if op[0] == ' ' {
+			continue
+		}
+		for k := ndx - contextLines; k <= ndx+contextLines; k++ {
+			if k >= 0 && k < len(ops) {
+				keep[k] = true
+			}
+		}
+	}
+
+	diff := []string{}
+	for ndx, op := range ops {
+		if !keep[ndx] {
+			continue
+		}
+		if ndx > 0 && !keep[ndx-1] && len(diff) > 0 {
+			diff = append(diff, "...")
+		}
+		diff = append(diff, op)
+	}
+	return diff
+}
+
+// PrintDiff prints the diff between two versions of a file, colorizing added and removed lines.
+func PrintDiff(beforeLabel string, before string, afterLabel string, after string) {
+	fmt.Println(color.RedString("--- %v", beforeLabel))
+	fmt.Println(color.GreenString("+++ %v", afterLabel))
+	for _, line := range DiffLines(before, after, 3) {
+		if strings.HasPrefix(line, "+") {
+			fmt.Println(color.GreenString(line))
+		} else if strings.HasPrefix(line, "-") {
+			fmt.Println(color.RedString(line))
+		} else {
+			fmt.Println(line)
+		}
+	}
+}
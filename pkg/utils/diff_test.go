@@ -0,0 +1,42 @@
+package utils_test
+
+import (
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	//test
+	diff := utils.DiffLines("Host bastion\n  User aws\n  Port 22\n", "Host bastion\n  User cloud-user\n  Port 22\n", 1)
+
+	//assert
+	require.Equal(t, []string{
+		" Host bastion",
+		"-  User aws",
+		"+  User cloud-user",
+		"   Port 22",
+	}, diff)
+}
+
+func TestDiffLines_Identical(t *testing.T) {
+	t.Parallel()
+
+	//test
+	diff := utils.DiffLines("Host bastion\n", "Host bastion\n", 3)
+
+	//assert
+	require.Empty(t, diff, "should not return any lines when content is identical")
+}
+
+func TestDiffLines_Context(t *testing.T) {
+	t.Parallel()
+
+	//test
+	diff := utils.DiffLines("a\nb\nc\nd\ne\nf\ng", "a\nB\nc\nd\ne\nf\nG", 0)
+
+	//assert
+	require.Equal(t, []string{"-b", "+B", "...", "-g", "+G"}, diff, "should separate hunks without context")
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/analogj/drawbridge/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io"
+)
+
+// Output formats supported by the global `--output` flag
+const (
+	OutputFormatText = "text"
+	OutputFormatJson = "json"
+	OutputFormatYaml = "yaml"
+)
+
+func ValidateOutputFormat(format string) error {
+	if !SliceIncludes([]string{OutputFormatText, OutputFormatJson, OutputFormatYaml}, format) {
+		return errors.InvalidArgumentsError(fmt.Sprintf("Output format must be one of text, json or yaml, not `%v`", format))
+	}
+	return nil
+}
+
+// IsStructuredOutput returns true when the output format is machine-readable (json or yaml)
+func IsStructuredOutput(format string) bool {
+	return format == OutputFormatJson || format == OutputFormatYaml
+}
+
+// PrintStructured serializes data as json or yaml.
+func PrintStructured(writer io.Writer, format string, data interface{}) error {
+	var content []byte
+	var err error
+	switch format {
+	case OutputFormatJson:
+		content, err = json.MarshalIndent(data, "", "  ")
+		content = append(content, '\n')
+	case OutputFormatYaml:
+		content, err = yaml.Marshal(data)
+	default:
+		return ValidateOutputFormat(format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	return err
+}
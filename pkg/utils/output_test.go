@@ -0,0 +1,46 @@
+package utils_test
+
+import (
+	"bytes"
+	"github.com/analogj/drawbridge/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPrintStructured_Json(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	buf := new(bytes.Buffer)
+
+	//test
+	err := utils.PrintStructured(buf, utils.OutputFormatJson, map[string]interface{}{"index": 1})
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"index\": 1\n}\n", buf.String())
+}
+
+func TestPrintStructured_Yaml(t *testing.T) {
+	t.Parallel()
+
+	//setup
+	buf := new(bytes.Buffer)
+
+	//test
+	err := utils.PrintStructured(buf, utils.OutputFormatYaml, map[string]interface{}{"index": 1})
+
+	//assert
+	require.NoError(t, err)
+	require.Equal(t, "index: 1\n", buf.String())
+}
+
+func TestPrintStructured_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	//test
+	err := utils.PrintStructured(new(bytes.Buffer), "xml", map[string]interface{}{"index": 1})
+
+	//assert
+	require.Error(t, err, "should raise an error for unknown output formats")
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"github.com/analogj/drawbridge/pkg/errors"
 	"github.com/fatih/color"
 	"golang.org/x/crypto/ssh/terminal"
 	"os"
@@ -11,7 +12,19 @@ import (
 	"syscall"
 )
 
+// NonInteractive disables all STDIN prompts. When set, any would-be prompt returns an InteractivePromptError instead.
+// It is enabled by the global `--non-interactive` flag, or automatically when STDIN is not a terminal.
+var NonInteractive = false
+
+// StdinIsTerminal returns true when drawbridge is attached to an interactive terminal
+func StdinIsTerminal() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
 func StdinQueryPassword(question string) (string, error) {
+	if NonInteractive {
+		return "", nonInteractiveError(question)
+	}
 
 	fmt.Println(color.BlueString(question))
 	bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
@@ -23,41 +36,53 @@ func StdinQueryPassword(question string) (string, error) {
 	return text, nil
 }
 
-func StdinQuery(question string) string {
+func StdinQuery(question string) (string, error) {
+	if NonInteractive {
+		return "", nonInteractiveError(question)
+	}
 
 	fmt.Println(color.BlueString(question))
 	reader := bufio.NewReader(os.Stdin)
 	text, _ := reader.ReadString('\n')
 	text = strings.TrimSpace(text)
-	return text
+	return text, nil
 }
 
-func StdinQueryBoolean(question string) bool {
+func StdinQueryBoolean(question string) (bool, error) {
 
-	text := StdinQuery(question)
+	text, err := StdinQuery(question)
+	if err != nil {
+		return false, err
+	}
 	text = strings.ToLower(text)
 
 	if text == "true" || text == "yes" || text == "y" {
-		return true
+		return true, nil
 	} else if text == "false" || text == "no" || text == "n" {
-		return false
+		return false, nil
 	} else {
 		color.Yellow("WARNING: invalid response only true/yes/y/false/no/n allowed not `%v`.\nAssuming `no`", text)
-		return false
+		return false, nil
 	}
 }
 
 func StdinQueryInt(question string) (int, error) {
 
-	text := StdinQuery(question)
+	text, err := StdinQuery(question)
+	if err != nil {
+		return 0, err
+	}
 	return StringToInt(text)
 }
 
-func StdinQueryRegex(message string, regexPattern string, friendlyPattern string) string {
+func StdinQueryRegex(message string, regexPattern string, friendlyPattern string) (string, error) {
 	for true {
 
 		//prompt the user to enter a valid choice
-		text := StdinQuery(fmt.Sprintf("%v (%s):", message, friendlyPattern))
+		text, err := StdinQuery(fmt.Sprintf("%v (%s):", message, friendlyPattern))
+		if err != nil {
+			return "", err
+		}
 
 		isValid, err := regexp.MatchString(regexPattern, text)
 
@@ -69,7 +94,13 @@ func StdinQueryRegex(message string, regexPattern string, friendlyPattern string
 			continue
 		}
 
-		return text
+		return text, nil
 	}
-	return ""
+	return "", nil
+}
+
+func nonInteractiveError(question string) error {
+	//only include the first line of multi-line confirmation prompts
+	question = strings.SplitN(strings.TrimSpace(question), "\n", 2)[0]
+	return errors.InteractivePromptError(fmt.Sprintf("Cannot prompt in non-interactive mode: %v", question))
 }
@@ -81,3 +81,17 @@ func LeftPad2Len(s string, padStr string, overallLen int) string {
 func StripIndent(multilineStr string) string {
 	return strings.Replace(multilineStr, "\t", "", -1)
 }
+
+// ShellQuote joins the arguments into a POSIX shell command line, single-quoting the arguments that contain anything
+// other than safe characters.
+func ShellQuote(args ...string) string {
+	quoted := []string{}
+	for _, arg := range args {
+		if len(arg) > 0 && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+			quoted = append(quoted, arg)
+		} else {
+			quoted = append(quoted, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -94,3 +94,23 @@ func TestRightPad(t *testing.T) {
 	require.Equal(t, "12345----------", utils.RightPad("12345", "-", 10))
 	require.Equal(t, "12345---", utils.RightPad("12345", "-", 3))
 }
+
+var shellQuoteTests = []struct {
+	n        []string // input
+	expected string   // expected result
+}{
+	{[]string{"ssh", "bastion", "-F", "/Users/jason/.ssh/drawbridge/prod-app-live-us-east-1"}, "ssh bastion -F /Users/jason/.ssh/drawbridge/prod-app-live-us-east-1"},
+	{[]string{"scp", "database-1.in:/var/log/my app.log", ""}, "scp 'database-1.in:/var/log/my app.log' ''"},
+	{[]string{"echo", "it's $HOME"}, `echo 'it'\''s $HOME'`},
+}
+
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+	for _, tt := range shellQuoteTests {
+		//test
+		actual := utils.ShellQuote(tt.n...)
+
+		//assert
+		require.Equal(t, tt.expected, actual, "should only quote arguments that contain unsafe characters")
+	}
+}